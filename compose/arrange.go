@@ -0,0 +1,200 @@
+package compose
+
+import (
+	"math"
+	"sort"
+
+	"git.yo2.cz/drahoslav/penego/draw"
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+// GridSize is distance between grid lines, nodes and waypoints are snapped to when moved
+var GridSize = 15.0
+
+type Alignment int
+
+const (
+	AlignLeft Alignment = iota
+	AlignCenter
+	AlignRight
+	AlignTop
+	AlignMiddle
+	AlignBottom
+)
+
+// ToggleSelected adds place or transition to selection or removes it from it
+func (comp Composition) ToggleSelected(node Composable) {
+	switch node.(type) {
+	case *net.Place, *net.Transition:
+		if comp.selection[node] {
+			delete(comp.selection, node)
+		} else {
+			comp.selection[node] = true
+		}
+	}
+}
+
+// SelectIn adds places and transitions with center inside of rectangle to selection
+// corners of rectangle can be given in any order
+func (comp Composition) SelectIn(x1, y1, x2, y2 float64) {
+	left, right := math.Min(x1, x2), math.Max(x1, x2)
+	top, bottom := math.Min(y1, y2), math.Max(y1, y2)
+	inside := func(pos draw.Pos) bool {
+		return pos.X >= left && pos.X <= right && pos.Y >= top && pos.Y <= bottom
+	}
+	for place, pos := range comp.places {
+		if inside(pos) {
+			comp.selection[place] = true
+		}
+	}
+	for tran, pos := range comp.transitions {
+		if inside(pos) {
+			comp.selection[tran] = true
+		}
+	}
+}
+
+func (comp Composition) IsSelected(node Composable) bool {
+	return comp.selection[node]
+}
+
+// returns selected nodes which are still part of composition
+func (comp Composition) Selected() []Composable {
+	nodes := []Composable{}
+	for node := range comp.selection {
+		if _, ok := comp.Position(node); ok {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+func (comp Composition) ClearSelection() {
+	for node := range comp.selection {
+		delete(comp.selection, node)
+	}
+}
+
+// aligns edges (or centers) of nodes to the outermost (or middle) one
+func (comp Composition) Align(nodes []Composable, alignment Alignment) {
+	if len(nodes) < 2 {
+		return
+	}
+	left, top := math.Inf(+1), math.Inf(+1)
+	right, bottom := math.Inf(-1), math.Inf(-1)
+	for _, node := range nodes {
+		pos, _ := comp.Position(node)
+		width, height := size(node)
+		left = math.Min(left, pos.X-width/2)
+		right = math.Max(right, pos.X+width/2)
+		top = math.Min(top, pos.Y-height/2)
+		bottom = math.Max(bottom, pos.Y+height/2)
+	}
+	for _, node := range nodes {
+		pos, _ := comp.Position(node)
+		width, height := size(node)
+		switch alignment {
+		case AlignLeft:
+			pos.X = left + width/2
+		case AlignCenter:
+			pos.X = (left + right) / 2
+		case AlignRight:
+			pos.X = right - width/2
+		case AlignTop:
+			pos.Y = top + height/2
+		case AlignMiddle:
+			pos.Y = (top + bottom) / 2
+		case AlignBottom:
+			pos.Y = bottom - height/2
+		}
+		comp.setPosition(node, pos)
+	}
+}
+
+// spreads nodes evenly between the first and the last one
+// distances from the first one are snapped to grid, so nodes stay on grid if the first one is
+func (comp Composition) Distribute(nodes []Composable, horizontally bool) {
+	if len(nodes) < 3 {
+		return
+	}
+	coord := func(pos draw.Pos) float64 {
+		if horizontally {
+			return pos.X
+		}
+		return pos.Y
+	}
+	poss := make([]draw.Pos, len(nodes))
+	for i, node := range nodes {
+		poss[i], _ = comp.Position(node)
+	}
+	sorted := make([]int, len(nodes))
+	for i := range sorted {
+		sorted[i] = i
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return coord(poss[sorted[i]]) < coord(poss[sorted[j]])
+	})
+
+	first, last := coord(poss[sorted[0]]), coord(poss[sorted[len(sorted)-1]])
+	step := (last - first) / float64(len(nodes)-1)
+	for i, index := range sorted {
+		pos := poss[index]
+		distance := math.Floor(float64(i)*step/GridSize+0.5) * GridSize
+		if i == len(sorted)-1 {
+			distance = last - first // the last one stays
+		}
+		if horizontally {
+			pos.X = first + distance
+		} else {
+			pos.Y = first + distance
+		}
+		comp.setPosition(nodes[index], pos)
+	}
+}
+
+// mirrors composition left to right, it stays centered at the same point
+func (comp Composition) MirrorHorizontally() {
+	centerX, _ := comp.FindCenter()
+	comp.transform(func(pos draw.Pos) draw.Pos {
+		return draw.Pos{2*centerX - pos.X, pos.Y}
+	})
+	comp.transformLabels(func(offset draw.Pos) draw.Pos {
+		return draw.Pos{-offset.X, offset.Y}
+	})
+}
+
+// mirrors composition upside down, it stays centered at the same point
+func (comp Composition) MirrorVertically() {
+	_, centerY := comp.FindCenter()
+	comp.transform(func(pos draw.Pos) draw.Pos {
+		return draw.Pos{pos.X, 2*centerY - pos.Y}
+	})
+	comp.transformLabels(func(offset draw.Pos) draw.Pos {
+		return draw.Pos{offset.X, -offset.Y}
+	})
+}
+
+// applies function to positions of all nodes and waypoints
+func (comp Composition) transform(f func(draw.Pos) draw.Pos) {
+	for place, pos := range comp.places {
+		comp.places[place] = f(pos)
+	}
+	for tran, pos := range comp.transitions {
+		comp.transitions[tran] = f(pos)
+	}
+	for _, poss := range comp.pathes {
+		for i, pos := range poss {
+			poss[i] = f(pos)
+		}
+	}
+}
+
+// sets position of place or transition without snapping it to grid
+func (comp Composition) setPosition(node Composable, pos draw.Pos) {
+	switch node := node.(type) {
+	case *net.Place:
+		comp.places[node] = pos
+	case *net.Transition:
+		comp.transitions[node] = pos
+	}
+}
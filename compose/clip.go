@@ -0,0 +1,92 @@
+package compose
+
+import (
+	"git.yo2.cz/drahoslav/penego/draw"
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+// Clip is copied part of net along with its composition, which can be pasted later
+type Clip struct {
+	subnet      net.Subnet
+	composition Composition
+}
+
+func (clip Clip) IsEmpty() bool {
+	return clip.subnet.IsEmpty()
+}
+
+// CopySelected returns clip of selected places and transitions, with arcs and their paths between them
+func (comp Composition) CopySelected() Clip {
+	places, transitions := net.Places{}, net.Transitions{}
+	for _, node := range comp.Selected() {
+		switch node := node.(type) {
+		case *net.Place:
+			places.Push(node)
+		case *net.Transition:
+			transitions.Push(node)
+		}
+	}
+	subnet := net.NewSubnet(places, transitions)
+	clip := Clip{subnet, New()}
+	comp.copyInto(clip.composition, corresponding(places, transitions, subnet), draw.Pos{})
+	return clip
+}
+
+// Paste adds copy of clip to network and composition, centered around x, y
+// pasted places and transitions are selected instead of the previous selection, so they can be moved together
+func (comp Composition) Paste(network *net.Net, clip Clip, x, y float64) {
+	if clip.IsEmpty() {
+		return
+	}
+	added := network.AddSubnet(clip.subnet)
+	left, top, right, bottom := clip.composition.bounds()
+	cx, cy := center(left, top, right, bottom)
+	shift := snap(x-cx, y-cy, GridSize) // pasted nodes stay on grid, if copied ones were
+	nodes := corresponding(clip.subnet.Places(), clip.subnet.Transitions(), added)
+
+	comp.ClearSelection()
+	clip.composition.copyInto(comp, nodes, shift)
+	for _, node := range nodes {
+		comp.selection[node] = true
+	}
+}
+
+// copies positions, labels and paths of nodes to another composition as positions of corresponding nodes
+// only paths between copied nodes are copied, all positions are shifted
+func (comp Composition) copyInto(another Composition, nodes map[Composable]Composable, shift draw.Pos) {
+	shifted := func(pos draw.Pos) draw.Pos {
+		return draw.Pos{pos.X + shift.X, pos.Y + shift.Y}
+	}
+	for node, copied := range nodes {
+		if pos, ok := comp.Position(node); ok {
+			another.setPosition(copied, shifted(pos))
+		}
+		if offset, ok := comp.labels[node]; ok {
+			another.labels[copied] = offset
+		}
+	}
+	for p, poss := range comp.pathes {
+		from, fromOk := nodes[p.from]
+		to, toOk := nodes[p.to]
+		if !fromOk || !toOk {
+			continue
+		}
+		copied := make([]draw.Pos, len(poss))
+		for i, pos := range poss {
+			copied[i] = shifted(pos)
+		}
+		another.pathes[&path{from, to}] = copied
+	}
+}
+
+// maps places and transitions to those of subnet at the same index
+func corresponding(places net.Places, transitions net.Transitions, subnet net.Subnet) map[Composable]Composable {
+	nodes := map[Composable]Composable{}
+	for i, place := range places {
+		nodes[place] = subnet.Places()[i]
+	}
+	for i, tran := range transitions {
+		nodes[tran] = subnet.Transitions()[i]
+	}
+	return nodes
+}
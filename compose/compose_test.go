@@ -1,6 +1,7 @@
 package compose
 
 import (
+	"strings"
 	"testing"
 
 	"git.yo2.cz/drahoslav/penego/draw"
@@ -17,6 +18,25 @@ func getComp() Composition {
 	return comp
 }
 
+// lineNetSource describes net p -> t[] -> q
+const lineNetSource = `
+	p ()
+	q ()
+	----
+	p -> t[] -> q
+`
+
+// getLineComp returns net given by source and its composition,
+// with first place, first transition and second place on a line
+func getLineComp(source string) (net.Net, Composition) {
+	network, _ := net.Parse(source)
+	comp := New()
+	comp.Move(network.Places()[0], 0, 0)
+	comp.Move(network.Transitions()[0], 90, 0)
+	comp.Move(network.Places()[1], 180, 0)
+	return network, comp
+}
+
 func TestCompositionFindCenter(test *testing.T) {
 	comp := getComp()
 
@@ -63,3 +83,332 @@ func TestCompositionCenterTo(test *testing.T) {
 		}
 	}
 }
+
+func TestCompositionStringParse(test *testing.T) {
+	network, _ := net.Parse(`
+		p ()
+		q ()
+		----
+		p -> t[] -> q
+		q -> [] -> p
+	`)
+	places, trans := network.Places(), network.Transitions()
+	comp := New()
+	comp.Move(places[0], 0, 0)
+	comp.Move(places[1], 90, 0)
+	comp.Move(trans[0], 45, 45)
+	comp.Move(trans[1], 45, -45)
+	comp.SetPathPositions(trans[0], places[1], []draw.Pos{{30, 60}, {60, 60}})
+	comp.SetPathPositions(places[1], trans[1], []draw.Pos{{15, 15}}) // anonymous transition
+
+	// anonymous transition can not be referred to, so it is left out along with its paths
+	str := comp.String()
+	expected := "p 0;0\nq 90;0\n----\nt 45;45\n----\nt->q 30;60 60;60\n"
+	if str != expected {
+		test.Errorf("composition should be written as\n%s\nnot\n%s", expected, str)
+	}
+
+	parsed := Parse(str, network)
+	poss := parsed.PathPositions(trans[0], places[1])
+	if len(poss) != 4 || poss[1] != (draw.Pos{30, 60}) || poss[2] != (draw.Pos{60, 60}) || poss[3] != (draw.Pos{90, 0}) {
+		test.Errorf("path should be parsed, got %v", poss)
+	}
+	if parsed.String() != str {
+		test.Errorf("composition should round-trip, got\n%s\ninstead of\n%s", parsed, str)
+	}
+}
+
+func TestCompositionMoveWaypoint(test *testing.T) {
+	network, _ := net.Parse(`
+		p ()
+		----
+		p -> t[]
+	`)
+	place, tran := network.Places()[0], network.Transitions()[0]
+	comp := New()
+	comp.Move(place, 0, 0)
+	comp.Move(tran, 90, 0)
+	comp.SetPathPositions(place, tran, []draw.Pos{{45, 45}})
+
+	waypoint := comp.HitTest(47, 43)
+	if _, ok := waypoint.(Waypoint); !ok {
+		test.Fatalf("waypoint should be hit, got %v", waypoint)
+	}
+	comp.GhostMove(waypoint, 45, 90)
+	if poss := comp.PathPositions(place, tran); poss[1] != (draw.Pos{45, 90}) {
+		test.Errorf("ghost position of waypoint should be used, got %v", poss)
+	}
+	comp.Move(waypoint, 60, 91)
+	if poss := comp.PathPositions(place, tran); len(poss) != 3 || poss[1] != (draw.Pos{60, 90}) {
+		test.Errorf("waypoint should be moved to snapped position, got %v", poss)
+	}
+}
+
+func TestCompositionAddRemoveWaypoint(test *testing.T) {
+	network, _ := net.Parse(`
+		p ()
+		----
+		p -> t[] -> p
+	`)
+	place, tran := network.Places()[0], network.Transitions()[0]
+	comp := New()
+	comp.Move(place, 0, 0)
+	comp.Move(tran, 90, 0)
+	comp.SetPathPositions(place, tran, []draw.Pos{{45, 45}})
+
+	segment := comp.HitTest(70, 22)
+	if _, ok := segment.(ArcSegment); !ok {
+		test.Fatalf("arc segment should be hit, got %v", segment)
+	}
+	waypoint := comp.AddWaypoint(segment.(ArcSegment), 70, 22)
+	poss := comp.PathPositions(place, tran)
+	if len(poss) != 4 || poss[1] != (draw.Pos{45, 45}) || poss[2] != (draw.Pos{60, 15}) {
+		test.Errorf("waypoint should be inserted after first one, got %v", poss)
+	}
+	if back := comp.PathPositions(tran, place); len(back) != 4 || back[1] != poss[2] {
+		test.Errorf("arc of opposite direction should share the path, got %v", back)
+	}
+
+	comp.RemoveWaypoint(waypoint)
+	comp.RemoveWaypoint(comp.HitTest(45, 45).(Waypoint))
+	if poss := comp.PathPositions(place, tran); len(poss) != 2 {
+		test.Errorf("all waypoints should be removed, got %v", poss)
+	}
+	if str := comp.String(); strings.Contains(str, "->") {
+		test.Errorf("empty path should not be written, got\n%s", str)
+	}
+}
+
+func TestCompositionRemove(test *testing.T) {
+	network, comp := getLineComp(lineNetSource)
+	p, q, t := network.Places()[0], network.Places()[1], network.Transitions()[0]
+	comp.SetPathPositions(p, t, []draw.Pos{{45, 45}})
+	comp.SetPathPositions(t, q, []draw.Pos{{135, 45}})
+	comp.ToggleSelected(p)
+
+	segment, ok := comp.HitTest(112, 22).(ArcSegment)
+	if !ok {
+		test.Fatalf("arc segment should be hit")
+	}
+	if from, to := segment.Ends(); from != t || to != q {
+		test.Errorf("segment should lead from t to q, got %v %v", from, to)
+	}
+
+	comp.Remove(p)
+	if _, ok := comp.Position(p); ok {
+		test.Errorf("place should be removed")
+	}
+	if len(comp.Selected()) != 0 || comp.IsSelected(p) {
+		test.Errorf("removed place should not be selected")
+	}
+	if poss := comp.PathPositions(p, t); len(poss) != 2 {
+		test.Errorf("path of removed place should be removed, got %v", poss)
+	}
+	if poss := comp.PathPositions(t, q); len(poss) != 3 {
+		test.Errorf("other paths should be kept, got %v", poss)
+	}
+}
+
+func TestCompositionCopyRestore(test *testing.T) {
+	network, _ := net.Parse(`
+		p ()
+		----
+		p -> t[]
+	`)
+	p, t := network.Places()[0], network.Transitions()[0]
+	comp := New()
+	comp.Move(p, 0, 0)
+	comp.Move(t, 90, 0)
+	comp.SetPathPositions(p, t, []draw.Pos{{45, 45}})
+	str := comp.String()
+
+	copied := comp.Copy()
+	comp.Rotate()
+	comp.Move(p, 30, 30)
+	if copied.String() != str {
+		test.Errorf("copy should not be changed with composition, got\n%s", copied)
+	}
+
+	comp.Restore(copied)
+	if comp.String() != str {
+		test.Errorf("composition should be restored, got\n%s\ninstead of\n%s", comp, str)
+	}
+}
+
+func TestCompositionMoveSelection(test *testing.T) {
+	network, comp := getLineComp(lineNetSource)
+	p, q, t := network.Places()[0], network.Places()[1], network.Transitions()[0]
+	comp.SetPathPositions(p, t, []draw.Pos{{45, 45}})
+
+	comp.SelectIn(100, 30, -30, -30)
+	if !comp.IsSelected(p) || !comp.IsSelected(t) || comp.IsSelected(q) {
+		test.Fatalf("nodes inside of rectangle should be selected, got %v", comp.Selected())
+	}
+	comp.GhostMoveSelection(t, 90, 30)
+	if poss := comp.PathPositions(p, t); poss[1] != (draw.Pos{45, 75}) {
+		test.Errorf("waypoint between selected nodes should be ghost moved, got %v", poss)
+	}
+	comp.MoveSelection(t, 91, 31)
+	if pos, _ := comp.Position(p); pos != (draw.Pos{0, 30}) {
+		test.Errorf("selected place should be moved along, got %v", pos)
+	}
+	if pos, _ := comp.Position(q); pos != (draw.Pos{180, 0}) {
+		test.Errorf("not selected place should stay, got %v", pos)
+	}
+	if poss := comp.PathPositions(p, t); len(poss) != 3 || poss[1] != (draw.Pos{45, 75}) {
+		test.Errorf("waypoint between selected nodes should be moved, got %v", poss)
+	}
+}
+
+func TestCompositionCenterToWithSelection(test *testing.T) {
+	network, comp := getLineComp(lineNetSource)
+	p, q, t := network.Places()[0], network.Places()[1], network.Transitions()[0]
+	comp.ToggleSelected(p)
+	comp.ToggleSelected(q)
+
+	comp.CenterTo(0, 0)
+	for node, x := range map[Composable]float64{p: -90, t: 0, q: 90} {
+		if pos, _ := comp.Position(node); pos.X != x {
+			test.Errorf("node %v should be moved to %v, not %v", id(node), x, pos.X)
+		}
+	}
+}
+
+func TestCompositionCopyPaste(test *testing.T) {
+	network, comp := getLineComp(lineNetSource)
+	p, t := network.Places()[0], network.Transitions()[0]
+	comp.SetPathPositions(p, t, []draw.Pos{{45, 45}})
+	comp.ToggleSelected(p)
+	comp.ToggleSelected(t)
+
+	clip := comp.CopySelected()
+	comp.Paste(&network, clip, 45, 300)
+	if len(network.Places()) != 3 || len(network.Transitions()) != 2 {
+		test.Fatalf("subnet should be pasted, got %s", network.String())
+	}
+	pasted := network.Transitions()[1]
+	if pasted.String() != "p1 -> t1[]" {
+		test.Errorf("pasted transition should be renamed and keep inner arc, got %s", pasted)
+	}
+	if pos, _ := comp.Position(pasted); pos != (draw.Pos{90, 285}) {
+		test.Errorf("pasted nodes should be centered around given point, got %v", pos)
+	}
+	if poss := comp.PathPositions(pasted.Origins[0].Place, pasted); len(poss) != 3 || poss[1] != (draw.Pos{45, 330}) {
+		test.Errorf("path between pasted nodes should be pasted, got %v", poss)
+	}
+	if len(comp.Selected()) != 2 || !comp.IsSelected(pasted) {
+		test.Errorf("pasted nodes should be selected, got %v", comp.Selected())
+	}
+}
+
+func TestCompositionAlign(test *testing.T) {
+	network, _ := net.Parse(`
+		p ()
+		q ()
+		----
+		p -> t[] -> q
+	`)
+	p, q, t := network.Places()[0], network.Places()[1], network.Transitions()[0]
+	comp := New()
+	comp.Move(p, 0, 0)
+	comp.Move(q, 90, 30)
+	comp.Move(t, 45, 75)
+	nodes := []Composable{p, q, t}
+
+	comp.Align(nodes, AlignLeft)
+	for _, node := range nodes {
+		pos, _ := comp.Position(node)
+		width, _ := size(node)
+		if left := pos.X - width/2; left != -draw.PLACE_RADIUS {
+			test.Errorf("left edge of %v should be %v, got %v", node, -draw.PLACE_RADIUS, left)
+		}
+	}
+
+	comp.Align(nodes, AlignMiddle)
+	for _, node := range nodes {
+		pos, _ := comp.Position(node)
+		if pos.Y != 43.5 { // between top of p (-24) and bottom of t (75+36)
+			test.Errorf("%v should be in the middle, got %v", node, pos)
+		}
+	}
+	if pos, _ := comp.Position(q); pos.X != 0 {
+		test.Errorf("align vertically should not change x, got %v", pos)
+	}
+}
+
+func TestCompositionDistribute(test *testing.T) {
+	comp := New()
+	nodes := []Composable{}
+	for _, x := range []float64{0, 90, 15, 30} {
+		place := &net.Place{}
+		comp.places[place] = draw.Pos{x, 15}
+		nodes = append(nodes, place)
+	}
+	comp.Distribute(nodes, true)
+	for i, x := range []float64{0, 90, 30, 60} {
+		if pos, _ := comp.Position(nodes[i]); pos != (draw.Pos{x, 15}) {
+			test.Errorf("node %d should be at %v, got %v", i, draw.Pos{x, 15}, pos)
+		}
+	}
+
+	// 50 apart is not on grid of 15
+	comp.places[nodes[1].(*net.Place)] = draw.Pos{0, 100}
+	comp.places[nodes[2].(*net.Place)] = draw.Pos{0, 20}
+	comp.Distribute(nodes[:3], false)
+	for i, y := range []float64{15, 100, 60} {
+		if pos, _ := comp.Position(nodes[i]); pos.Y != y {
+			test.Errorf("node %d should be snapped to %v, got %v", i, y, pos.Y)
+		}
+	}
+}
+
+func TestCompositionMirror(test *testing.T) {
+	network, _ := net.Parse(`
+		p ()
+		----
+		p -> t[]
+	`)
+	place, tran := network.Places()[0], network.Transitions()[0]
+	comp := New()
+	comp.Move(place, 0, 0)
+	comp.Move(tran, 90, 30)
+	comp.SetPathPositions(place, tran, []draw.Pos{{30, 60}})
+
+	comp.MirrorHorizontally()
+	poss := comp.PathPositions(place, tran)
+	if poss[0] != (draw.Pos{90, 0}) || poss[1] != (draw.Pos{60, 60}) || poss[2] != (draw.Pos{0, 30}) {
+		test.Errorf("composition should be mirrored horizontally, got %v", poss)
+	}
+	comp.MirrorVertically()
+	poss = comp.PathPositions(place, tran)
+	if poss[0] != (draw.Pos{90, 30}) || poss[1] != (draw.Pos{60, -30}) || poss[2] != (draw.Pos{0, 0}) {
+		test.Errorf("composition should be mirrored vertically, got %v", poss)
+	}
+}
+
+func TestCompositionGridSize(test *testing.T) {
+	defer func(grid float64) { GridSize = grid }(GridSize)
+	GridSize = 10
+
+	comp := getComp()
+	place := &net.Place{}
+	comp.Move(place, 37, 42)
+	if pos, _ := comp.Position(place); pos != (draw.Pos{30, 40}) {
+		test.Errorf("position should be snapped to grid of 10, got %v", pos)
+	}
+}
+
+func TestPointAlong(test *testing.T) {
+	path := []draw.Pos{{0, 0}, {30, 0}, {30, 10}}
+	for part, expected := range map[float64]draw.Pos{
+		0:    {0, 0},
+		0.5:  {20, 0},
+		0.75: {30, 0},
+		1:    {30, 10},
+		2:    {30, 10},
+	} {
+		if pos := pointAlong(path, part); pos != expected {
+			test.Errorf("point %v along path should be %v, got %v", part, expected, pos)
+		}
+	}
+}
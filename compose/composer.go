@@ -5,6 +5,7 @@ package compose
 import (
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -20,11 +21,33 @@ type path struct {
 type Composable interface {
 }
 
+// Waypoint is intermediate point of path of arc
+// it can be hit, moved and ghost moved the same way as places and transitions
+type Waypoint struct {
+	path  *path
+	index int
+}
+
+// ArcSegment is line of arc between two consecutive positions of its path
+type ArcSegment struct {
+	from  Composable
+	to    Composable
+	index int
+}
+
+// Ends returns nodes connected by arc of the segment, in direction of the arc
+func (segment ArcSegment) Ends() (from, to Composable) {
+	return segment.from, segment.to
+}
+
 type Composition struct {
 	places      map[*net.Place]draw.Pos
 	transitions map[*net.Transition]draw.Pos
 	pathes      map[*path][]draw.Pos
 	ghosts      map[Composable]draw.Pos
+	selection   map[Composable]bool
+	labels      map[Composable]draw.Pos // offsets of labels from their nodes
+	heatmap     *heatmap
 }
 
 func New() Composition {
@@ -33,6 +56,9 @@ func New() Composition {
 		make(map[*net.Transition]draw.Pos),
 		make(map[*path][]draw.Pos),
 		make(map[Composable]draw.Pos),
+		make(map[Composable]bool),
+		make(map[Composable]draw.Pos),
+		&heatmap{},
 	}
 }
 
@@ -50,34 +76,209 @@ func (comp Composition) PathPositions(from Composable, to Composable) []draw.Pos
 	fromPos, toPos := getPos(from), getPos(to)
 
 	positions := []draw.Pos{}
+	reversed := []draw.Pos{}
 
 	// insert intermediate path positions
 	for path, poss := range comp.pathes {
-		if path.from == from && path.to == to {
-			for _, pos := range poss {
+		for i, pos := range poss {
+			if ghostPos, isGhosted := comp.ghosts[Waypoint{path, i}]; isGhosted {
+				pos = ghostPos
+			}
+			if path.from == from && path.to == to {
 				positions = append(positions, pos)
 			}
-		}
-		if path.from == to && path.to == from {
-			for _, pos := range poss {
-				positions = append([]draw.Pos{pos}, positions...)
+			if path.from == to && path.to == from {
+				reversed = append([]draw.Pos{pos}, reversed...)
 			}
 		}
 	}
+	// use path of opposite direction only if there is none in this one
+	if len(positions) == 0 {
+		positions = reversed
+	}
 
 	positions = append([]draw.Pos{fromPos}, positions...)
 	positions = append(positions, toPos)
 	return positions
 }
 
+// returns position of place or transition
+func (comp Composition) Position(node Composable) (draw.Pos, bool) {
+	var pos draw.Pos
+	var ok bool
+	switch node := node.(type) {
+	case *net.Place:
+		pos, ok = comp.places[node]
+	case *net.Transition:
+		pos, ok = comp.transitions[node]
+	}
+	return pos, ok
+}
+
+// sets intermediate positions of path between two nodes
+// empty poss removes the path, so only end positions are used
+func (comp Composition) SetPathPositions(from Composable, to Composable, poss []draw.Pos) {
+	comp.setPath(from, to, poss)
+}
+
+func (comp Composition) setPath(from Composable, to Composable, poss []draw.Pos) *path {
+	for path := range comp.pathes {
+		if path.from == from && path.to == to {
+			delete(comp.pathes, path)
+		}
+	}
+	if len(poss) == 0 {
+		return nil
+	}
+	path := &path{from, to}
+	comp.pathes[path] = poss
+	return path
+}
+
+// AddWaypoint inserts new intermediate position to the arc segment
+// returns waypoint, so it can be moved further
+func (comp Composition) AddWaypoint(segment ArcSegment, x, y float64) Waypoint {
+	poss := comp.PathPositions(segment.from, segment.to)
+	waypoints := append([]draw.Pos{}, poss[1:segment.index+1]...)
+	waypoints = append(waypoints, snap(x, y, GridSize))
+	waypoints = append(waypoints, poss[segment.index+1:len(poss)-1]...)
+
+	// path of opposite direction is kept only if there is an arc using it
+	if !hasArc(segment.to, segment.from) {
+		comp.setPath(segment.to, segment.from, nil)
+	}
+	return Waypoint{comp.setPath(segment.from, segment.to, waypoints), segment.index}
+}
+
+// RemoveWaypoint removes intermediate position of arc
+func (comp Composition) RemoveWaypoint(waypoint Waypoint) {
+	poss, ok := comp.pathes[waypoint.path]
+	if !ok || waypoint.index >= len(poss) {
+		return
+	}
+	poss = append(poss[:waypoint.index:waypoint.index], poss[waypoint.index+1:]...)
+	if len(poss) == 0 {
+		delete(comp.pathes, waypoint.path)
+	} else {
+		comp.pathes[waypoint.path] = poss
+	}
+	delete(comp.ghosts, waypoint)
+}
+
+// Copy returns copy of positions of nodes, paths and labels of composition, which can be restored later
+// ghosts, selection and heatmap are not copied
+func (comp Composition) Copy() Composition {
+	copied := New()
+	copied.Restore(comp)
+	return copied
+}
+
+// Restore sets positions of nodes, paths and labels to those of another composition
+// selection is kept for nodes which are still there
+func (comp Composition) Restore(another Composition) {
+	for place := range comp.places {
+		delete(comp.places, place)
+	}
+	for place, pos := range another.places {
+		comp.places[place] = pos
+	}
+	for tran := range comp.transitions {
+		delete(comp.transitions, tran)
+	}
+	for tran, pos := range another.transitions {
+		comp.transitions[tran] = pos
+	}
+	for path := range comp.pathes {
+		delete(comp.pathes, path)
+	}
+	for path, poss := range another.pathes {
+		comp.pathes[path] = append([]draw.Pos{}, poss...)
+	}
+	for node := range comp.labels {
+		delete(comp.labels, node)
+	}
+	for node, offset := range another.labels {
+		comp.labels[node] = offset
+	}
+	for node := range comp.ghosts {
+		delete(comp.ghosts, node)
+	}
+}
+
+// Remove removes place or transition from composition along with paths of its arcs
+func (comp Composition) Remove(node Composable) {
+	switch node := node.(type) {
+	case *net.Place:
+		delete(comp.places, node)
+	case *net.Transition:
+		delete(comp.transitions, node)
+	}
+	for path := range comp.pathes {
+		if path.from == node || path.to == node {
+			delete(comp.pathes, path)
+		}
+	}
+	delete(comp.ghosts, node)
+	delete(comp.selection, node)
+	delete(comp.labels, node)
+}
+
+// returns id of place or transition
+func id(node Composable) string {
+	switch node := node.(type) {
+	case *net.Place:
+		return node.Id
+	case *net.Transition:
+		return node.Id
+	}
+	return ""
+}
+
+// returns composition in format `id x;y` for nodes (or `id x;y dx;dy` if offset of label is known)
+// and `from->to x;y x;y...` for intermediate positions of arcs
+// lines are sorted, so same composition is always written the same
+// transitions without id can not be referred to, so neither their positions nor paths of their arcs are written
 func (comp Composition) String() string {
-	str := ""
+	nodeString := func(node Composable, pos draw.Pos) string {
+		if id(node) == "" {
+			return ""
+		}
+		str := fmt.Sprintf("%s %v;%v", id(node), pos.X, pos.Y)
+		if offset, ok := comp.labels[node]; ok && description(node) != "" {
+			str += fmt.Sprintf(" %v;%v", offset.X, offset.Y)
+		}
+		return str + "\n"
+	}
+	places := []string{}
 	for place, pos := range comp.places {
-		str += fmt.Sprintf("%s %v;%v\n", place.Id, pos.X, pos.Y)
+		places = append(places, nodeString(place, pos))
 	}
-	str += fmt.Sprintf("----\n")
+	transitions := []string{}
 	for transition, pos := range comp.transitions {
-		str += fmt.Sprintf("%s %v;%v\n", transition.Id, pos.X, pos.Y)
+		transitions = append(transitions, nodeString(transition, pos))
+	}
+	pathes := []string{}
+	for path, poss := range comp.pathes {
+		from, to := id(path.from), id(path.to)
+		if len(poss) == 0 || from == "" || to == "" {
+			continue
+		}
+		str := from + "->" + to
+		for _, pos := range poss {
+			str += fmt.Sprintf(" %v;%v", pos.X, pos.Y)
+		}
+		pathes = append(pathes, str+"\n")
+	}
+	sort.Strings(places)
+	sort.Strings(transitions)
+	sort.Strings(pathes)
+
+	str := strings.Join(places, "")
+	str += fmt.Sprintf("----\n")
+	str += strings.Join(transitions, "")
+	if len(pathes) > 0 {
+		str += fmt.Sprintf("----\n")
+		str += strings.Join(pathes, "")
 	}
 	return str
 }
@@ -93,16 +294,76 @@ func (comp Composition) HitTest(x, y float64) Composable {
 			return transition
 		}
 	}
+	for path, poss := range comp.pathes {
+		for i, pos := range poss {
+			if hitWaypoint(x, y, pos) {
+				return Waypoint{path, i}
+			}
+		}
+	}
+	if label, ok := comp.hitLabel(x, y); ok {
+		return label
+	}
+	hitPath := func(from, to Composable) Composable {
+		poss := comp.PathPositions(from, to)
+		for i := 0; i < len(poss)-1; i++ {
+			if hitSegment(x, y, poss[i], poss[i+1]) {
+				return ArcSegment{from, to, i}
+			}
+		}
+		return nil
+	}
+	for tran := range comp.transitions {
+		for _, arc := range tran.Origins {
+			if _, ok := comp.places[arc.Place]; ok {
+				if segment := hitPath(arc.Place, tran); segment != nil {
+					return segment
+				}
+			}
+		}
+		for _, arc := range tran.Targets {
+			if _, ok := comp.places[arc.Place]; ok {
+				if segment := hitPath(tran, arc.Place); segment != nil {
+					return segment
+				}
+			}
+		}
+	}
 	return nil
 }
 
+// Move moves node to position snapped to grid
 func (comp Composition) Move(node Composable, x, y float64) {
-	pos := snap(x, y, 15)
+	if label, isLabel := node.(Label); isLabel { // labels are not snapped, they are kept relative to their node
+		if nodePos, ok := comp.Position(label.node); ok {
+			comp.labels[label.node] = draw.Pos{x - nodePos.X, y - nodePos.Y}
+		}
+		delete(comp.ghosts, node)
+		return
+	}
+	comp.move(node, snap(x, y, GridSize))
+}
+
+// MoveSelection moves node as Move does,
+// selected node is moved along with the rest of selection and bends of arcs between selected nodes
+func (comp Composition) MoveSelection(node Composable, x, y float64) {
+	for other, otherPos := range comp.movedWith(node, snap(x, y, GridSize)) {
+		comp.move(other, otherPos)
+	}
+	comp.Move(node, x, y)
+}
+
+// sets position of node or waypoint
+func (comp Composition) move(node Composable, pos draw.Pos) {
 	switch node := node.(type) {
 	case *net.Transition:
 		comp.transitions[node] = pos
 	case *net.Place:
 		comp.places[node] = pos
+	case Waypoint:
+		if poss, ok := comp.pathes[node.path]; ok && node.index < len(poss) {
+			poss[node.index] = pos
+		}
 	}
 	delete(comp.ghosts, node)
 }
@@ -146,8 +407,57 @@ func (comp Composition) FindCenter() (float64, float64) {
 	return center(left, top, right, bottom)
 }
 
+// Bounds returns edges of rectangle, which contains all nodes, their labels, bends of arcs and legends of heatmap
+// all are zero for empty composition
+func (comp Composition) Bounds() (left, top, right, bottom float64) {
+	left, top, right, bottom = comp.nodesBounds()
+	if comp.heatOn() {
+		_, transitions := comp.legendPositions()
+		right = math.Max(right, transitions.X+draw.LEGEND_WIDTH)
+		bottom = math.Max(bottom, transitions.Y+draw.LEGEND_HEIGHT)
+	}
+	return
+}
+
+// returns bounds of composition without legends
+func (comp Composition) nodesBounds() (left, top, right, bottom float64) {
+	left, top = math.Inf(+1), math.Inf(+1)
+	right, bottom = math.Inf(-1), math.Inf(-1)
+	enhance := func(b box) {
+		left, top = math.Min(left, b.left), math.Min(top, b.top)
+		right, bottom = math.Max(right, b.right), math.Max(bottom, b.bottom)
+	}
+	addNode := func(node Composable, pos draw.Pos) {
+		width, height := size(node)
+		enhance(newBox(pos, width, height))
+		if text := description(node); text != "" {
+			width, height := draw.LabelSize(text)
+			enhance(newBox(comp.labelPosition(node, pos), width, height))
+		}
+	}
+	for place, pos := range comp.places {
+		addNode(place, pos)
+	}
+	for tran, pos := range comp.transitions {
+		addNode(tran, pos)
+		if attrs := tran.TimeFunc.String(); attrs != "" { // drawn under transition
+			width, height := draw.LabelSize(attrs)
+			enhance(newBox(draw.Pos{pos.X, pos.Y + draw.TRANSITION_HEIGHT/2 + 20 - height/2}, width, height))
+		}
+	}
+	for _, poss := range comp.pathes {
+		for _, pos := range poss {
+			enhance(newBox(pos, 0, 0))
+		}
+	}
+	if math.IsInf(left, 0) {
+		return 0, 0, 0, 0
+	}
+	return
+}
+
 // move whole composition so its center is at x, y
-// note that is uses Movew which snaps to multiples of 15, so it might not end up exactly on those positions
+// note that is uses Move which snaps to multiples of GridSize, so it might not end up exactly on those positions
 func (comp Composition) CenterTo(x, y float64) {
 	centerX, centerY := comp.FindCenter()
 
@@ -185,69 +495,155 @@ func (comp Composition) Rotate() {
 			poss[i] = draw.Pos{-pos.Y, pos.X}
 		}
 	}
+
+	comp.transformLabels(func(offset draw.Pos) draw.Pos {
+		return draw.Pos{-offset.Y, offset.X}
+	})
 }
 
 // align nodes to vertical center axis
+// each column of nodes (and waypoints) with the same x is centered to the center of whole composition
 func (comp Composition) AlignY() {
-	minX, minY := math.Inf(+1), math.Inf(+1)
-	maxX, maxY := math.Inf(-1), math.Inf(-1)
-	byX := map[float64][]Composable{}
-	byY := map[float64][]Composable{}
+	_, centerY := comp.FindCenter()
+	minY, maxY := map[float64]float64{}, map[float64]float64{}
 
-	assignPos := func(node Composable, pos draw.Pos) {
-		x, y := pos.X, pos.Y
-		if x < minX {
-			minX = x
-		}
-		if x > maxX {
-			maxX = x
+	assignPos := func(pos draw.Pos) {
+		if _, ok := minY[pos.X]; !ok {
+			minY[pos.X], maxY[pos.X] = pos.Y, pos.Y
 		}
-		if y < minY {
-			minY = y
-		}
-		if y > maxY {
-			maxY = y
-		}
-		if _, ok := byX[x]; !ok {
-			byX[x] = []Composable{}
-		}
-		if _, ok := byY[y]; !ok {
-			byY[y] = []Composable{}
+		minY[pos.X] = math.Min(minY[pos.X], pos.Y)
+		maxY[pos.X] = math.Max(maxY[pos.X], pos.Y)
+	}
+	for _, pos := range comp.places {
+		assignPos(pos)
+	}
+	for _, pos := range comp.transitions {
+		assignPos(pos)
+	}
+	for _, poss := range comp.pathes {
+		for _, pos := range poss {
+			assignPos(pos)
 		}
-		byX[x] = append(byX[x], node)
-		byY[y] = append(byY[y], node)
+	}
+
+	aligned := func(pos draw.Pos) draw.Pos {
+		_, columnCenterY := center(pos.X, minY[pos.X], pos.X, maxY[pos.X])
+		return snap(pos.X, pos.Y+centerY-columnCenterY, 15)
 	}
 	for place, pos := range comp.places {
-		assignPos(place, pos)
+		comp.places[place] = aligned(pos)
 	}
 	for tran, pos := range comp.transitions {
-		assignPos(tran, pos)
+		comp.transitions[tran] = aligned(pos)
 	}
-	for path, poss := range comp.pathes {
-		for _, pos := range poss {
-			assignPos(path, pos)
+	for _, poss := range comp.pathes {
+		for i, pos := range poss {
+			poss[i] = aligned(pos)
 		}
 	}
-
-	// TODO
 }
 
 func (comp Composition) GhostMove(node Composable, x, y float64) {
-	comp.ghosts[node] = snap(x, y, 15)
+	if _, isLabel := node.(Label); isLabel {
+		comp.ghosts[node] = draw.Pos{x, y}
+		return
+	}
+	comp.ghosts[node] = snap(x, y, GridSize)
+}
+
+// GhostMoveSelection ghost moves node as GhostMove does, along with the rest of selection as MoveSelection does
+func (comp Composition) GhostMoveSelection(node Composable, x, y float64) {
+	for other, otherPos := range comp.movedWith(node, snap(x, y, GridSize)) {
+		comp.ghosts[other] = otherPos
+	}
+	comp.GhostMove(node, x, y)
+}
+
+// returns new positions of other selected nodes and waypoints of paths between selected nodes,
+// when selected node is moved to pos, so they keep their position relative to it
+// nothing else is moved with node, which is not selected
+func (comp Composition) movedWith(node Composable, pos draw.Pos) map[Composable]draw.Pos {
+	moved := map[Composable]draw.Pos{}
+	nodePos, ok := comp.Position(node)
+	if !ok || !comp.selection[node] {
+		return moved
+	}
+	dx, dy := pos.X-nodePos.X, pos.Y-nodePos.Y
+	for other := range comp.selection {
+		if otherPos, ok := comp.Position(other); ok && other != node {
+			moved[other] = draw.Pos{otherPos.X + dx, otherPos.Y + dy}
+		}
+	}
+	for path, poss := range comp.pathes {
+		if comp.selection[path.from] && comp.selection[path.to] {
+			for i, pos := range poss {
+				moved[Waypoint{path, i}] = draw.Pos{pos.X + dx, pos.Y + dy}
+			}
+		}
+	}
+	return moved
 }
 
+// DrawFlowWith draws tokens moved by firing of transition
+// in the first half of progress tokens travel from origin places to transition, in the second half to target places
+// tokens are drawn only if drawer is draw.TokenDrawer
+func (comp Composition) DrawFlowWith(drawer draw.Drawer, tran *net.Transition, progress float64) {
+	tokenDrawer, ok := drawer.(draw.TokenDrawer)
+	if _, isComposed := comp.transitions[tran]; !ok || !isComposed {
+		return
+	}
+	drawer.SetStyle(draw.DefaultStyle)
+	if progress < 0.5 {
+		for _, arc := range tran.Origins {
+			if arc.Type == net.InhibitorArc || arc.Place.Hidden() {
+				continue
+			}
+			tokenDrawer.DrawToken(pointAlong(comp.PathPositions(arc.Place, tran), progress*2), arc.Weight)
+		}
+	} else {
+		for _, arc := range tran.Targets {
+			if arc.Place.Hidden() {
+				continue
+			}
+			tokenDrawer.DrawToken(pointAlong(comp.PathPositions(tran, arc.Place), progress*2-1), arc.Weight)
+		}
+	}
+}
+
+// TransitionState tells whether transition is enabled and how far is its next event
+// progress is negative if no event is scheduled, see net.Simulation.TransitionState
+type TransitionState func(tran *net.Transition) (enabled bool, progress float64)
+
 func (comp Composition) DrawWith(drawer draw.Drawer) {
+	comp.DrawStateWith(drawer, nil)
+}
+
+// DrawStateWith draws composition along with state of transitions during simulation
+// state is drawn only if drawer is draw.StateDrawer
+func (comp Composition) DrawStateWith(drawer draw.Drawer, state TransitionState) {
+	// nodes are drawn in their own colours (or colours of heatmap), arcs in colours of theme
+	maxPlaces, maxTransitions := 1.0, 1.0
+	if comp.heatOn() {
+		maxPlaces, maxTransitions = comp.heatMax()
+	}
 	setStyle := func(node Composable) func(Composable) {
 		if _, isGhosted := comp.ghosts[node]; isGhosted {
-			drawer.SetStyle(draw.FadedStyle)
+			drawer.SetStyle(comp.heatStyle(node, nodeStyle(node, draw.FadedStyle), maxPlaces, maxTransitions))
 			return func(Composable) {
 				drawer.SetStyle(draw.FadedStyle)
 			}
+		} else if comp.selection[node] {
+			drawer.SetStyle(comp.heatStyle(node, nodeStyle(node, draw.HighlightedStyle), maxPlaces, maxTransitions))
+			return func(Composable) {
+				drawer.SetStyle(draw.HighlightedStyle)
+			}
 		} else {
-			drawer.SetStyle(draw.DefaultStyle)
+			drawer.SetStyle(comp.heatStyle(node, nodeStyle(node, draw.DefaultStyle), maxPlaces, maxTransitions))
 			return func(node Composable) {
 				if _, isGhosted := comp.ghosts[node]; isGhosted {
 					drawer.SetStyle(draw.FadedStyle)
+				} else if comp.selection[node] {
+					drawer.SetStyle(draw.HighlightedStyle)
 				} else {
 					drawer.SetStyle(draw.DefaultStyle)
 				}
@@ -255,6 +651,13 @@ func (comp Composition) DrawWith(drawer draw.Drawer) {
 		}
 	}
 
+	nodeDrawer, refersNodes := drawer.(draw.NodeDrawer)
+	setNodes := func(from, to Composable) {
+		if refersNodes {
+			nodeDrawer.SetNodes(from, to)
+		}
+	}
+
 	// draw arcs
 	for tran, _ := range comp.transitions {
 		orSetStyle := setStyle(tran)
@@ -263,6 +666,7 @@ func (comp Composition) DrawWith(drawer draw.Drawer) {
 				continue
 			}
 			orSetStyle(arc.Place)
+			setNodes(arc.Place, tran)
 			if arc.Type == net.InhibitorArc {
 				drawer.DrawInhibitorArc(comp.PathPositions(arc.Place, tran))
 			} else {
@@ -274,6 +678,7 @@ func (comp Composition) DrawWith(drawer draw.Drawer) {
 				continue
 			}
 			orSetStyle(arc.Place)
+			setNodes(tran, arc.Place)
 			drawer.DrawOutArc(comp.PathPositions(tran, arc.Place), arc.Weight)
 		}
 	}
@@ -281,13 +686,37 @@ func (comp Composition) DrawWith(drawer draw.Drawer) {
 	// draw all places
 	for place, pos := range comp.places {
 		setStyle(place)
-		drawer.DrawPlace(pos, place.Tokens, place.Description)
+		setNodes(place, nil)
+		drawer.DrawPlace(pos, place.Tokens, nodeDescription(drawer, place))
 	}
 
 	// draw all transtitions
 	for tran, pos := range comp.transitions {
 		setStyle(tran)
-		drawer.DrawTransition(pos, tran.TimeFunc.String(), tran.Description)
+		setNodes(tran, nil)
+		drawer.DrawTransition(pos, tran.TimeFunc.String(), nodeDescription(drawer, tran))
+	}
+	setNodes(nil, nil) // the rest is not part of any node
+
+	// draw which transitions are enabled and progress of their events
+	if stateDrawer, ok := drawer.(draw.StateDrawer); ok && state != nil {
+		for tran, pos := range comp.transitions {
+			if enabled, progress := state(tran); enabled || progress >= 0 {
+				stateDrawer.DrawTransitionState(pos, enabled, progress)
+			}
+		}
+	}
+
+	comp.drawLegends(drawer, maxPlaces, maxTransitions)
+
+	// draw labels over nodes
+	for place, pos := range comp.places {
+		setStyle(place)
+		comp.drawLabel(drawer, place, pos)
+	}
+	for tran, pos := range comp.transitions {
+		setStyle(tran)
+		comp.drawLabel(drawer, tran, pos)
 	}
 
 	// draw moving items last
@@ -296,7 +725,8 @@ func (comp Composition) DrawWith(drawer draw.Drawer) {
 		switch node := node.(type) {
 		case *net.Place:
 			place := node
-			drawer.DrawPlace(pos, place.Tokens, place.Description)
+			drawer.DrawPlace(pos, place.Tokens, nodeDescription(drawer, place))
+			comp.drawLabel(drawer, place, pos)
 			for tran, _ := range comp.transitions {
 				for _, arc := range tran.Origins {
 					if arc.Place == place {
@@ -315,7 +745,8 @@ func (comp Composition) DrawWith(drawer draw.Drawer) {
 			}
 		case *net.Transition:
 			tran := node
-			drawer.DrawTransition(pos, tran.TimeFunc.String(), tran.Description)
+			drawer.DrawTransition(pos, tran.TimeFunc.String(), nodeDescription(drawer, tran))
+			comp.drawLabel(drawer, tran, pos)
 			for _, arc := range tran.Origins {
 				if arc.Place.Hidden() {
 					continue
@@ -332,11 +763,45 @@ func (comp Composition) DrawWith(drawer draw.Drawer) {
 				}
 				drawer.DrawOutArc(comp.PathPositions(node, arc.Place), arc.Weight)
 			}
+		case Waypoint:
+			comp.drawArcsBetween(drawer, node.path.from, node.path.to)
+		case Label:
+			if nodePos, ok := comp.Position(node.node); ok {
+				comp.drawLabel(drawer, node.node, nodePos)
+			}
 		}
 	}
 
 }
 
+// draws arcs connecting place and transition in any direction
+func (comp Composition) drawArcsBetween(drawer draw.Drawer, a, b Composable) {
+	place, isPlace := a.(*net.Place)
+	tran, isTran := b.(*net.Transition)
+	if !isPlace || !isTran {
+		place, isPlace = b.(*net.Place)
+		tran, isTran = a.(*net.Transition)
+	}
+	if !isPlace || !isTran {
+		return
+	}
+	for _, arc := range tran.Origins {
+		if arc.Place != place {
+			continue
+		}
+		if arc.Type == net.InhibitorArc {
+			drawer.DrawInhibitorArc(comp.PathPositions(place, tran))
+		} else {
+			drawer.DrawInArc(comp.PathPositions(place, tran), arc.Weight)
+		}
+	}
+	for _, arc := range tran.Targets {
+		if arc.Place == place {
+			drawer.DrawOutArc(comp.PathPositions(tran, place), arc.Weight)
+		}
+	}
+}
+
 // basic "dumb" way to draw a net
 func GetSimple(network net.Net) Composition {
 	places := network.Places()
@@ -384,12 +849,26 @@ func Parse(str string, network net.Net) Composition {
 	composition := New()
 	lines := strings.Split(str, "\n")
 	for _, line := range lines {
-		parts := strings.Split(line, " ")
+		parts := strings.Fields(line)
+		if len(parts) == 0 {
+			continue
+		}
 		id := parts[0]
-		if len(parts) > 1 {
-			poss := strings.Split(parts[1], ";")
-			x, _ := strconv.ParseFloat(poss[0], 64)
-			y, _ := strconv.ParseFloat(poss[1], 64)
+		if ends := strings.Split(id, "->"); len(ends) == 2 {
+			from, to := findNode(network, ends[0]), findNode(network, ends[1])
+			if from == nil || to == nil {
+				continue
+			}
+			poss := []draw.Pos{}
+			for _, part := range parts[1:] {
+				if pos, ok := parsePos(part); ok {
+					poss = append(poss, pos)
+				}
+			}
+			composition.SetPathPositions(from, to, poss)
+		} else if len(parts) > 1 {
+			pos, _ := parsePos(parts[1])
+			x, y := pos.X, pos.Y
 			for _, tran := range network.Transitions() {
 				if tran.Id == id {
 					composition.transitions[tran] = draw.Pos{x, y}
@@ -400,7 +879,78 @@ func Parse(str string, network net.Net) Composition {
 					composition.places[place] = draw.Pos{x, y}
 				}
 			}
+			if len(parts) > 2 {
+				if offset, ok := parsePos(parts[2]); ok {
+					if node := findNode(network, id); node != nil {
+						composition.labels[node] = offset
+					}
+				}
+			}
 		}
 	}
 	return composition
 }
+
+// returns style with own colours of place or transition
+func nodeStyle(node Composable, style draw.Style) draw.Style {
+	var colorStr, fillStr string
+	switch node := node.(type) {
+	case *net.Place:
+		colorStr, fillStr = node.Color, node.Fill
+	case *net.Transition:
+		colorStr, fillStr = node.Color, node.Fill
+	}
+	color, _ := draw.ParseColor(colorStr)
+	fill, _ := draw.ParseColor(fillStr)
+	return style.WithColors(color, fill)
+}
+
+// returns whether there is an arc leading from one node to another
+func hasArc(from, to Composable) bool {
+	switch from := from.(type) {
+	case *net.Place:
+		if tran, ok := to.(*net.Transition); ok {
+			for _, arc := range tran.Origins {
+				if arc.Place == from {
+					return true
+				}
+			}
+		}
+	case *net.Transition:
+		for _, arc := range from.Targets {
+			if arc.Place == to {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// returns place or transition of given id
+func findNode(network net.Net, id string) Composable {
+	if id == "" {
+		return nil
+	}
+	for _, place := range network.Places() {
+		if place.Id == id {
+			return place
+		}
+	}
+	for _, tran := range network.Transitions() {
+		if tran.Id == id {
+			return tran
+		}
+	}
+	return nil
+}
+
+// parses `x;y`
+func parsePos(str string) (draw.Pos, bool) {
+	poss := strings.Split(str, ";")
+	if len(poss) != 2 {
+		return draw.Pos{}, false
+	}
+	x, errX := strconv.ParseFloat(poss[0], 64)
+	y, errY := strconv.ParseFloat(poss[1], 64)
+	return draw.Pos{x, y}, errX == nil && errY == nil
+}
@@ -0,0 +1,180 @@
+package compose
+
+import (
+	"math"
+
+	"git.yo2.cz/drahoslav/penego/draw"
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+const (
+	FORCE_ITERATIONS = 500
+	forceLength      = 90.0 // ideal length of arc
+)
+
+// nodes and arcs of net as used by force directed and orthogonal composers
+type forceGraph struct {
+	nodes []Composable
+	index map[Composable]int
+	arcs  [][2]int
+}
+
+func loadForceGraph(network net.Net) forceGraph {
+	g := forceGraph{index: map[Composable]int{}}
+	for _, place := range network.Places() {
+		g.index[place] = len(g.nodes)
+		g.nodes = append(g.nodes, place)
+	}
+	for _, tran := range network.Transitions() {
+		g.index[tran] = len(g.nodes)
+		g.nodes = append(g.nodes, tran)
+	}
+	for _, tran := range network.Transitions() {
+		for _, arc := range tran.Origins {
+			if i, ok := g.index[arc.Place]; ok {
+				g.arcs = append(g.arcs, [2]int{i, g.index[tran]})
+			}
+		}
+		for _, arc := range tran.Targets {
+			if i, ok := g.index[arc.Place]; ok {
+				g.arcs = append(g.arcs, [2]int{g.index[tran], i})
+			}
+		}
+	}
+	return g
+}
+
+// Force directed method for graph drawing
+// based on algorithm by Fruchterman and Reingold
+// nodes repel each other and arcs pull their ends together,
+// nodes of the same kind (place or transition) repel more, so places and transitions tend to alternate
+func GetForce(network net.Net) Composition {
+	g := loadForceGraph(network)
+	n := len(g.nodes)
+	comp := New()
+	if n == 0 {
+		return comp
+	}
+
+	// start on circle, so result does not depend on chance
+	xs, ys := make([]float64, n), make([]float64, n)
+	radius := forceLength * float64(n) / (2 * math.Pi)
+	for i := range g.nodes {
+		angle := 2 * math.Pi * float64(i) / float64(n)
+		xs[i], ys[i] = radius*math.Cos(angle), radius*math.Sin(angle)
+	}
+
+	isPlace := func(i int) bool {
+		_, ok := g.nodes[i].(*net.Place)
+		return ok
+	}
+
+	k := forceLength
+	temperature := radius/2 + k
+	dxs, dys := make([]float64, n), make([]float64, n)
+	for iter := 0; iter < FORCE_ITERATIONS; iter++ {
+		for i := range dxs {
+			// gravity keeps disconnected parts together
+			dxs[i], dys[i] = -xs[i]*0.01, -ys[i]*0.01
+		}
+		// repulsive forces
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				dx, dy := xs[i]-xs[j], ys[i]-ys[j]
+				d := math.Hypot(dx, dy)
+				if d < 1 {
+					// nodes at the same spot are pushed apart in direction given by their order
+					angle := float64(i*n + j)
+					dx, dy, d = math.Cos(angle), math.Sin(angle), 1
+				}
+				force := k * k / d
+				if isPlace(i) == isPlace(j) {
+					force *= 1.5
+				}
+				dxs[i] += dx / d * force
+				dys[i] += dy / d * force
+				dxs[j] -= dx / d * force
+				dys[j] -= dy / d * force
+			}
+		}
+		// attractive forces
+		for _, arc := range g.arcs {
+			i, j := arc[0], arc[1]
+			dx, dy := xs[i]-xs[j], ys[i]-ys[j]
+			d := math.Hypot(dx, dy)
+			if d < 1 {
+				continue
+			}
+			force := d * d / k
+			dxs[i] -= dx / d * force
+			dys[i] -= dy / d * force
+			dxs[j] += dx / d * force
+			dys[j] += dy / d * force
+		}
+		// move limited by temperature
+		for i := range xs {
+			d := math.Hypot(dxs[i], dys[i])
+			if d < 1e-9 {
+				continue
+			}
+			step := math.Min(d, temperature)
+			xs[i] += dxs[i] / d * step
+			ys[i] += dys[i] / d * step
+		}
+		temperature = math.Max(temperature*0.99, 1)
+	}
+
+	for i, node := range g.nodes {
+		pos := comp.freePosition(snap(xs[i], ys[i], 15), 90)
+		switch node := node.(type) {
+		case *net.Place:
+			comp.places[node] = pos
+		case *net.Transition:
+			comp.transitions[node] = pos
+		}
+	}
+	comp.CenterTo(0, 0)
+	return comp
+}
+
+// Orthogonal method for graph drawing
+// nodes of force directed composition are moved to grid
+// and arcs are routed using horizontal and vertical lines only
+func GetOrthogonal(network net.Net) Composition {
+	const CELL = 90.0
+
+	g := loadForceGraph(network)
+	force := GetForce(network)
+	comp := New()
+
+	for _, node := range g.nodes {
+		pos, _ := force.Position(node)
+		pos = draw.Pos{CELL * math.Floor(pos.X/CELL+0.5), CELL * math.Floor(pos.Y/CELL+0.5)}
+		pos = comp.freePosition(pos, CELL)
+		switch node := node.(type) {
+		case *net.Place:
+			comp.places[node] = pos
+		case *net.Transition:
+			comp.transitions[node] = pos
+		}
+	}
+
+	routed := map[[2]int]bool{}
+	for _, arc := range g.arcs {
+		from, to := g.nodes[arc[0]], g.nodes[arc[1]]
+		a, _ := comp.Position(from)
+		b, _ := comp.Position(to)
+		if a.X == b.X || a.Y == b.Y {
+			continue // straight already
+		}
+		// horizontal, vertical in the middle between nodes, horizontal again
+		mx := 15 * math.Floor((a.X+b.X)/30+0.5)
+		if routed[[2]int{arc[1], arc[0]}] {
+			mx += 15 // arc of opposite direction would overlap
+		}
+		routed[arc] = true
+		comp.SetPathPositions(from, to, []draw.Pos{{mx, a.Y}, {mx, b.Y}})
+	}
+	comp.CenterTo(0, 0)
+	return comp
+}
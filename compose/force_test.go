@@ -0,0 +1,41 @@
+package compose
+
+import (
+	"testing"
+
+	"git.yo2.cz/drahoslav/penego/draw"
+)
+
+func TestForce(test *testing.T) {
+	network := getNet()
+	comp := GetForce(network)
+	positions := map[draw.Pos]bool{}
+	for _, node := range loadForceGraph(network).nodes {
+		pos, ok := comp.Position(node)
+		if !ok {
+			test.Errorf("%s should be placed", id(node))
+		}
+		if positions[pos] {
+			test.Errorf("%s should not be at the same position as other node %v", id(node), pos)
+		} else {
+			positions[pos] = true
+		}
+	}
+	if again := GetForce(network); again.String() != comp.String() {
+		test.Errorf("force directed composition should be deterministic")
+	}
+}
+
+func TestOrthogonal(test *testing.T) {
+	network := getNet()
+	comp := GetOrthogonal(network)
+	g := loadForceGraph(network)
+	for _, arc := range g.arcs {
+		poss := comp.PathPositions(g.nodes[arc[0]], g.nodes[arc[1]])
+		for i := 1; i < len(poss); i++ {
+			if poss[i-1].X != poss[i].X && poss[i-1].Y != poss[i].Y {
+				test.Errorf("arc %s->%s should be orthogonal, got %v", id(g.nodes[arc[0]]), id(g.nodes[arc[1]]), poss)
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package compose
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"git.yo2.cz/drahoslav/penego/draw"
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+type HeatMeasure int
+
+const (
+	HeatOff       HeatMeasure = iota
+	HeatTokens                // mean number of tokens of places, throughput of transitions
+	HeatOccupancy             // part of time places have token, part of time transitions are enabled
+)
+
+const legendGap = 20.0 // space between net and legends
+
+type heatmap struct {
+	stats   *net.Statistics
+	measure HeatMeasure
+}
+
+// SetHeatmap colours places and transitions by statistics of simulation and draws legend under net
+// heatmap is turned off by nil statistics or HeatOff measure
+func (comp Composition) SetHeatmap(stats *net.Statistics, measure HeatMeasure) {
+	comp.heatmap.stats, comp.heatmap.measure = stats, measure
+}
+
+func (comp Composition) heatOn() bool {
+	return comp.heatmap.stats != nil && comp.heatmap.measure != HeatOff
+}
+
+// returns value of current measure for place or transition
+func (comp Composition) heatValue(node Composable) float64 {
+	stats := comp.heatmap.stats
+	switch node := node.(type) {
+	case *net.Place:
+		if comp.heatmap.measure == HeatTokens {
+			return stats.MeanTokens(node)
+		}
+		return stats.Occupancy(node)
+	case *net.Transition:
+		if comp.heatmap.measure == HeatTokens {
+			return stats.Throughput(node)
+		}
+		return stats.Utilization(node)
+	}
+	return 0
+}
+
+// returns values of current measure, which are drawn as the hottest colour, for places and for transitions
+func (comp Composition) heatMax() (places, transitions float64) {
+	if comp.heatmap.measure == HeatOccupancy {
+		return 1, 1
+	}
+	for place := range comp.places {
+		places = math.Max(places, comp.heatValue(place))
+	}
+	for tran := range comp.transitions {
+		transitions = math.Max(transitions, comp.heatValue(tran))
+	}
+	if places == 0 {
+		places = 1
+	}
+	if transitions == 0 {
+		transitions = 1
+	}
+	return
+}
+
+// returns style with fill of place or transition given by heatmap
+func (comp Composition) heatStyle(node Composable, style draw.Style, maxPlaces, maxTransitions float64) draw.Style {
+	if !comp.heatOn() {
+		return style
+	}
+	max := maxPlaces
+	if _, isTran := node.(*net.Transition); isTran {
+		max = maxTransitions
+	}
+	return style.WithColors(color.RGBA{}, draw.HeatColor(comp.heatValue(node)/max))
+}
+
+// returns top left corners of legends of places and transitions
+func (comp Composition) legendPositions() (places, transitions draw.Pos) {
+	left, _, _, bottom := comp.nodesBounds()
+	places = draw.Pos{left, bottom + legendGap}
+	transitions = draw.Pos{left + draw.LEGEND_WIDTH + legendGap, bottom + legendGap}
+	return
+}
+
+// draws legends of heatmap, if drawer can draw them
+func (comp Composition) drawLegends(drawer draw.Drawer, maxPlaces, maxTransitions float64) {
+	legendDrawer, ok := drawer.(draw.LegendDrawer)
+	if !ok || !comp.heatOn() {
+		return
+	}
+	placesPos, transitionsPos := comp.legendPositions()
+	drawer.SetStyle(draw.DefaultStyle)
+	if comp.heatmap.measure == HeatTokens {
+		legendDrawer.DrawLegend(placesPos, "mean tokens", "0", fmt.Sprintf("%.3g", maxPlaces))
+		legendDrawer.DrawLegend(transitionsPos, "throughput /s", "0", fmt.Sprintf("%.3g", maxTransitions))
+	} else {
+		legendDrawer.DrawLegend(placesPos, "occupancy", "0%", "100%")
+		legendDrawer.DrawLegend(transitionsPos, "utilization", "0%", "100%")
+	}
+}
@@ -0,0 +1,58 @@
+package compose
+
+import (
+	"testing"
+	"time"
+
+	"git.yo2.cz/drahoslav/penego/draw"
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+func TestHeatmap(test *testing.T) {
+	network, comp := getLineComp(`
+		p (1)
+		q ()
+		----
+		p -> t[3s] -> q
+		q -> u[1s] -> p
+	`)
+	p, q := network.Places()[0], network.Places()[1]
+	comp.Move(network.Transitions()[1], 90, 90)
+
+	sim := net.NewSimulation(0, 8*time.Second, network)
+	sim.DoEveryStateChange(nil)
+	sim.Init()
+	for sim.Step() {
+	}
+
+	left, top, right, bottom := comp.Bounds()
+	comp.SetHeatmap(sim.Statistics(), HeatOccupancy)
+	maxPlaces, maxTransitions := comp.heatMax()
+	if maxPlaces != 1 || maxTransitions != 1 {
+		test.Errorf("occupancy should be scaled to 100%%, got %v %v", maxPlaces, maxTransitions)
+	}
+	_, fill := comp.heatStyle(p, draw.DefaultStyle, maxPlaces, maxTransitions).Colors()
+	if fill != draw.HeatColor(0.75) {
+		test.Errorf("p should be filled by colour of 75%% occupancy, got %v", fill)
+	}
+
+	comp.SetHeatmap(sim.Statistics(), HeatTokens)
+	maxPlaces, maxTransitions = comp.heatMax()
+	if maxPlaces != 0.75 {
+		test.Errorf("mean tokens should be scaled to the most used place, got %v", maxPlaces)
+	}
+	_, fill = comp.heatStyle(q, draw.DefaultStyle, maxPlaces, maxTransitions).Colors()
+	if fill != draw.HeatColor(0.25/0.75) {
+		test.Errorf("q should be filled by colour relative to p, got %v", fill)
+	}
+
+	l, tp, r, b := comp.Bounds()
+	if l != left || tp != top || r < right || b != bottom+legendGap+draw.LEGEND_HEIGHT {
+		test.Errorf("bounds should include legends under net, got %v %v %v %v", l, tp, r, b)
+	}
+
+	comp.SetHeatmap(nil, HeatTokens)
+	if _, fill := comp.heatStyle(p, draw.DefaultStyle, 1, 1).Colors(); fill.A != 0 {
+		test.Errorf("heatmap should be off without statistics")
+	}
+}
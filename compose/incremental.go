@@ -0,0 +1,154 @@
+package compose
+
+import (
+	"git.yo2.cz/drahoslav/penego/draw"
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+// GetIterativeIncremental lays out net the same way as GetIterative,
+// but keeps positions of nodes found by id in pinned compositions
+func GetIterativeIncremental(network net.Net, pinned ...Composition) Composition {
+	return Incremental(network, GetIterative, pinned...)
+}
+
+// Incremental returns composition of net where nodes keep their positions from pinned compositions
+// (nodes are matched by id, earlier compositions take precedence)
+// and only nodes without position are placed using composer, near their already placed neighbours
+func Incremental(network net.Net, composer func(net.Net) Composition, pinned ...Composition) Composition {
+	const BASE = 90.0
+
+	comp := New()
+	nodes := []Composable{}
+	for _, place := range network.Places() {
+		nodes = append(nodes, place)
+	}
+	for _, tran := range network.Transitions() {
+		nodes = append(nodes, tran)
+	}
+
+	byId := map[string]Composable{}
+	for _, node := range nodes {
+		if id := id(node); id != "" {
+			byId[id] = node
+		}
+	}
+	// returns node of network matching node of pinned composition
+	match := func(node Composable) Composable {
+		if id := id(node); id != "" {
+			return byId[id]
+		}
+		return nil
+	}
+	set := func(node Composable, pos draw.Pos) {
+		switch node := node.(type) {
+		case *net.Place:
+			comp.places[node] = pos
+		case *net.Transition:
+			comp.transitions[node] = pos
+		}
+	}
+
+	// pin nodes
+	for i := len(pinned) - 1; i >= 0; i-- {
+		for place, pos := range pinned[i].places {
+			if node := match(place); node != nil {
+				set(node, pos)
+			}
+		}
+		for tran, pos := range pinned[i].transitions {
+			if node := match(tran); node != nil {
+				set(node, pos)
+			}
+		}
+	}
+	for i := len(pinned) - 1; i >= 0; i-- {
+		for node, offset := range pinned[i].labels {
+			if node := match(node); node != nil {
+				comp.labels[node] = offset
+			}
+		}
+	}
+	for i := len(pinned) - 1; i >= 0; i-- {
+		for path, poss := range pinned[i].pathes {
+			from, to := match(path.from), match(path.to)
+			if from != nil && to != nil {
+				comp.SetPathPositions(from, to, append([]draw.Pos{}, poss...))
+			}
+		}
+	}
+
+	unplaced := []Composable{}
+	for _, node := range nodes {
+		if _, ok := comp.Position(node); !ok {
+			unplaced = append(unplaced, node)
+		}
+	}
+	if len(unplaced) == 0 {
+		return comp
+	}
+	layout := composer(network)
+	if len(unplaced) == len(nodes) {
+		return layout
+	}
+
+	neighbours := map[Composable][]Composable{}
+	for _, tran := range network.Transitions() {
+		for _, arcs := range []net.Arcs{tran.Origins, tran.Targets} {
+			for _, arc := range arcs {
+				if arc.Place.Hidden() {
+					continue
+				}
+				neighbours[tran] = append(neighbours[tran], arc.Place)
+				neighbours[arc.Place] = append(neighbours[arc.Place], tran)
+			}
+		}
+	}
+
+	// average shift of pinned nodes against layout, used for nodes without placed neighbours
+	shiftX, shiftY := 0.0, 0.0
+	for _, node := range nodes {
+		if pos, ok := comp.Position(node); ok {
+			layoutPos, _ := layout.Position(node)
+			shiftX += pos.X - layoutPos.X
+			shiftY += pos.Y - layoutPos.Y
+		}
+	}
+	pinnedCount := float64(len(nodes) - len(unplaced))
+	shiftX, shiftY = shiftX/pinnedCount, shiftY/pinnedCount
+
+	for len(unplaced) > 0 {
+		// prefer nodes with placed neighbours, so they get close to them
+		index := 0
+		placed := []Composable{}
+		for i, node := range unplaced {
+			placed = placed[:0]
+			for _, neighbour := range neighbours[node] {
+				if _, ok := comp.Position(neighbour); ok {
+					placed = append(placed, neighbour)
+				}
+			}
+			if len(placed) > 0 {
+				index = i
+				break
+			}
+		}
+		node := unplaced[index]
+		unplaced = append(unplaced[:index], unplaced[index+1:]...)
+
+		layoutPos, _ := layout.Position(node)
+		x, y := layoutPos.X+shiftX, layoutPos.Y+shiftY
+		if len(placed) > 0 {
+			// keep relative position to neighbours from layout
+			x, y = layoutPos.X, layoutPos.Y
+			for _, neighbour := range placed {
+				pos, _ := comp.Position(neighbour)
+				neighbourLayoutPos, _ := layout.Position(neighbour)
+				x += (pos.X - neighbourLayoutPos.X) / float64(len(placed))
+				y += (pos.Y - neighbourLayoutPos.Y) / float64(len(placed))
+			}
+		}
+		set(node, comp.freePosition(snap(x, y, 15), BASE))
+	}
+
+	return comp
+}
@@ -1,7 +1,6 @@
 package compose
 
 import (
-	"git.yo2.cz/drahoslav/penego/draw"
 	"git.yo2.cz/drahoslav/penego/net"
 )
 
@@ -140,7 +139,7 @@ func (n *node) outEdges(edges []*edge) []*edge {
 }
 
 func (n *node) isPath() bool {
-	_, isPath := n.Composable.(path)
+	_, isPath := n.Composable.(*path)
 	return isPath
 }
 
@@ -196,6 +195,15 @@ func (g graph) transpose() graph {
 	return g
 }
 
+// returns successors of each node
+func (g graph) successors() map[*node][]*node {
+	successors := make(map[*node][]*node, len(g.nodes))
+	for _, e := range g.edges {
+		successors[e.from] = append(successors[e.from], e.to)
+	}
+	return successors
+}
+
 // depth first search algorithm
 // only enter to node if cond is fulfilled
 // calls onopen for each node when opened
@@ -204,6 +212,22 @@ func dfs(g graph, v0 *node,
 	cond func(v *node) bool,
 	onopen func(v *node),
 	onclose func(v *node),
+) (in, out map[*node]int) {
+	in, out = dfsFrom(g.successors(), v0, cond, onopen, onclose)
+	for _, node := range g.nodes {
+		if _, visited := in[node]; !visited {
+			in[node], out[node] = 0, 0
+		}
+	}
+	return
+}
+
+// same as dfs, but uses successors computed beforehand,
+// so it can be called repeatedly without going through all edges every time
+func dfsFrom(successors map[*node][]*node, v0 *node,
+	cond func(v *node) bool,
+	onopen func(v *node),
+	onclose func(v *node),
 ) (in, out map[*node]int) {
 	const (
 		notfound int = iota
@@ -213,11 +237,6 @@ func dfs(g graph, v0 *node,
 	state := map[*node]int{}
 	in = map[*node]int{}
 	out = map[*node]int{}
-	for _, node := range g.nodes {
-		state[node] = notfound
-		in[node] = 0
-		out[node] = 0
-	}
 	step := 0
 
 	var dfs2 func(*node)
@@ -232,12 +251,9 @@ func dfs(g graph, v0 *node,
 		step++
 		in[v] = step
 
-		for _, edge := range g.edges {
-			if edge.from == v {
-				w := edge.to
-				if state[w] == notfound {
-					dfs2(w)
-				}
+		for _, w := range successors[v] {
+			if state[w] == notfound {
+				dfs2(w)
 			}
 		}
 
@@ -266,18 +282,20 @@ func (g graph) components() (map[*node]*node, []*node) {
 	markVisited := func(v *node) { visited[v] = true }
 	addToStack := func(v *node) { stack = append(stack, v) }
 
+	successorsT := gt.successors()
 	for _, v := range gt.nodes {
-		dfs(gt, v, notVisited, markVisited, addToStack)
+		dfsFrom(successorsT, v, notVisited, markVisited, addToStack)
 	}
 
 	notAssigned := func(v *node) bool {
 		_, isIn := components[v]
 		return !isIn
 	}
+	successors := g.successors()
 	for i := len(stack) - 1; i >= 0; i-- {
 		v := stack[i]
 		assignComp := func(w *node) { components[w] = v; isCompTimes[v]++ }
-		dfs(g, v, notAssigned, assignComp, nil)
+		dfsFrom(successors, v, notAssigned, assignComp, nil)
 	}
 
 	// count components which consists of more than one node
@@ -300,6 +318,10 @@ func (g graph) acyclic() graph {
 
 	visited := map[*node]bool{}
 	stack := map[*node]bool{}
+	outEdges := map[*node][]int{} // indexes of out edges
+	for i, e := range g.edges {
+		outEdges[e.from] = append(outEdges[e.from], i)
+	}
 
 	var dfs func(v *node)
 	dfs = func(v *node) {
@@ -308,15 +330,14 @@ func (g graph) acyclic() graph {
 		}
 		visited[v] = true
 		stack[v] = true
-		for i, e := range g.edges {
-			if e.from == v {
-				u := e.to
-				if stack[u] {
-					g.edges[i] = e.reversed()
-				} else {
-					if !visited[u] {
-						dfs(u)
-					}
+		for _, i := range outEdges[v] {
+			e := g.edges[i]
+			u := e.to
+			if stack[u] {
+				g.edges[i] = e.reversed()
+			} else {
+				if !visited[u] {
+					dfs(u)
 				}
 			}
 		}
@@ -331,64 +352,65 @@ func (g graph) acyclic() graph {
 	return g
 }
 
-// Iterative method for graph drawing
-// based on dot algorithm and work of Warfield, Sugiyamaet at al.
-func GetIterative(network net.Net) Composition {
-
-	graph := loadGraph(network)
-
-	// assign rank λ(v) to each node v
-	// edge e = (v, w)
-	// lenght of edge l(e) ≥ δ(e)
-	// l(e) = λ(w) − λ(v)
-
-	// make graph acyclic by reversing edges
-	graph = graph.acyclic()
-
-	rank(&graph)
-	ordering(&graph)
-	comp := positions(&graph)
-	// makeSplines()
-
-	return comp
-}
-
-
-func positions(g *graph) Composition {
-	// TODO make this better
-	comp := New()
-	rankSizes := map[int]int{}
-	maxRankSize := 0
-
-	for _, n := range g.nodes {
-		if _, ok := rankSizes[n.rank]; !ok {
-			rankSizes[n.rank] = 0
+// returns weakly connected components of graph, each as separate graph
+// nodes and edges keep their order
+func (g graph) connectedComponents() []graph {
+	parent := make(map[*node]*node, len(g.nodes))
+	var find func(n *node) *node
+	find = func(n *node) *node {
+		p, ok := parent[n]
+		if !ok || p == n {
+			return n
 		}
-		rankSizes[n.rank]++
-		if rankSizes[n.rank] > maxRankSize {
-			maxRankSize = rankSizes[n.rank]
+		root := find(p)
+		parent[n] = root
+		return root
+	}
+	for _, e := range g.edges {
+		a, b := find(e.from), find(e.to)
+		if a != b {
+			parent[a] = b
 		}
 	}
 
+	index := map[*node]int{}
+	components := []graph{}
 	for _, n := range g.nodes {
-		x := float64(n.rank * 90)
-		y := (float64(n.order) + (float64(maxRankSize - rankSizes[n.rank]) / 2)) * 90 
-		pos := draw.Pos{x, y}
-		switch node := n.Composable.(type) {
-		case *net.Transition:
-			comp.transitions[node] = pos
-		case *net.Place:
-			comp.places[node] = pos
-		case *path:
-			if _, ok := comp.pathes[node]; !ok {
-				comp.pathes[node] = []draw.Pos{}
-			}
-			// append pos front
-			comp.pathes[node] = append([]draw.Pos{pos}, comp.pathes[node]...)
+		root := find(n)
+		if _, ok := index[root]; !ok {
+			index[root] = len(components)
+			components = append(components, newGraph())
 		}
+		components[index[root]].nodes = append(components[index[root]].nodes, n)
+	}
+	for _, e := range g.edges {
+		i := index[find(e.from)]
+		components[i].edges = append(components[i].edges, e)
+	}
+	return components
+}
+
+// Iterative method for graph drawing
+// based on dot algorithm and work of Warfield, Sugiyamaet at al.
+// each connected component is composed separately and then they are packed together
+func GetIterative(network net.Net) Composition {
+	compositions := []Composition{}
+
+	for _, graph := range loadGraph(network).connectedComponents() {
+		// assign rank λ(v) to each node v
+		// edge e = (v, w)
+		// lenght of edge l(e) ≥ δ(e)
+		// l(e) = λ(w) − λ(v)
+
+		// make graph acyclic by reversing edges
+		graph = graph.acyclic()
+
+		rank(&graph)
+		ordering(&graph)
+		comp := positions(&graph)
+		routeArcs(comp)
+		compositions = append(compositions, comp)
 	}
-	comp.AlignY()
-	comp.CenterTo(0, 0)
 
-	return comp
-}
\ No newline at end of file
+	return pack(compositions)
+}
@@ -6,28 +6,6 @@ import (
 
 const MAX_ORDERING_ITER = 32
 
-// returns ordered orders of all incident nodes in given rank
-func (n *node) adjOrders(g *graph, order order) []int {
-	orders := []int{}
-	for _, e := range n.inEdges(g.edges) {
-		for i, rank_n := range order {
-			if e.from == rank_n {
-				orders = append(orders, i)
-			}
-		}
-	}
-	for _, e := range n.outEdges(g.edges) {
-		for i, rank_n := range order {
-			if e.from == rank_n {
-				orders = append(orders, i)
-			}
-		}
-	}
-
-	sort.Ints(orders)
-	return orders
-}
-
 type order []*node
 type orders map[int]order
 
@@ -42,6 +20,13 @@ func (o order) Swap(i, j int) {
 	o[i], o[j] = o[j], o[i]
 }
 
+// sets order of nodes to their index
+func (o order) renumber() {
+	for i, n := range o {
+		n.order = i
+	}
+}
+
 func (os orders) clone() orders {
 	clone := make(orders, len(os))
 	for i, _ := range os {
@@ -51,97 +36,188 @@ func (os orders) clone() orders {
 	return clone
 }
 
+// returns ranks in ascending order
+func (os orders) ranks() []int {
+	ranks := make([]int, 0, len(os))
+	for rank := range os {
+		ranks = append(ranks, rank)
+	}
+	sort.Ints(ranks)
+	return ranks
+}
+
+// neighbours of nodes in previous and next rank
+// computed once, so ordering does not have to go through all edges again and again
+type adjacency struct {
+	upper map[*node][]*node
+	lower map[*node][]*node
+}
+
+func newAdjacency(g *graph) adjacency {
+	adj := adjacency{
+		upper: make(map[*node][]*node, len(g.nodes)),
+		lower: make(map[*node][]*node, len(g.nodes)),
+	}
+	for _, e := range g.edges {
+		a, b := e.from, e.to
+		if a.rank > b.rank {
+			a, b = b, a
+		}
+		if b.rank-a.rank != 1 {
+			continue
+		}
+		adj.lower[a] = append(adj.lower[a], b)
+		adj.upper[b] = append(adj.upper[b], a)
+	}
+	return adj
+}
+
 // Orders nodes withing same rank using median wighting based algorithm described here:
 // http://www.graphviz.org/Documentation/TSE93.pdf
 func ordering(g *graph) {
 	fillPathNodes(g)
-
-	keepTrying := MAX_ORDERING_ITER
+	adj := newAdjacency(g)
 
 	orders := initOrder(g)
 	bestOrders := orders.clone()
-	for i := 0; keepTrying > 0; i++ {
+	bestCrossings := crossings(adj, orders)
+	keepTrying := MAX_ORDERING_ITER
+	for i := 0; keepTrying > 0 && bestCrossings > 0 && i < 4*MAX_ORDERING_ITER; i++ {
 		keepTrying--
-		weightMedian(g, orders, i)
-		transpose(g, orders)
-		if crossings(g, orders) < crossings(g, bestOrders) {
+		weightMedian(adj, orders, i)
+		transpose(adj, orders)
+		if count := crossings(adj, orders); count < bestCrossings {
 			bestOrders = orders.clone()
+			bestCrossings = count
 			keepTrying = MAX_ORDERING_ITER
 		}
 	}
 
 	// apply best orders to nodes
 	for _, nodes := range bestOrders {
-		for i, n := range nodes {
-			n.order = i
+		nodes.renumber()
+	}
+}
+
+// returns median of orders of neighbours
+// -1 if there are none
+func medianValue(neighbours []*node) float64 {
+	P := make([]int, len(neighbours))
+	for i, n := range neighbours {
+		P[i] = n.order
+	}
+	sort.Ints(P)
+	mid := len(P) / 2
+	if len(P) == 0 {
+		return -1.0
+	}
+	if len(P)%2 == 1 {
+		return float64(P[mid])
+	}
+	if len(P) == 2 {
+		return float64(P[0]+P[1]) / 2
+	} else {
+		left := float64(P[mid-1] - P[0])
+		right := float64(P[len(P)-1] - P[mid])
+		if left+right == 0 {
+			return float64(P[mid-1]+P[mid]) / 2
 		}
+		return (float64(P[mid-1])*right + float64(P[mid])*left) / (left + right)
 	}
 }
 
-// re-assign weight value to each node
-func weightMedian(g *graph, orders orders, iter int) {
-	medianValue := func(n *node, rank int) float64 {
-		P := n.adjOrders(g, orders[rank])
-		mid := len(P) / 2
-		if len(P) == 0 {
-			return -1.0
+// re-assign weight value to each node and sort ranks by it
+// even iterations go from first rank down using upper neighbours, odd ones up using lower ones
+// nodes without neighbours keep their place
+func weightMedian(adj adjacency, orders orders, iter int) {
+	ranks := orders.ranks()
+	neighbours := adj.upper
+	if iter%2 == 1 {
+		for i, j := 0, len(ranks)-1; i < j; i, j = i+1, j-1 {
+			ranks[i], ranks[j] = ranks[j], ranks[i]
 		}
-		if len(P)%2 == 1 {
-			return float64(P[mid])
+		neighbours = adj.lower
+	}
+
+	for _, rank := range ranks {
+		nodes := orders[rank]
+		movable := order{}
+		for _, n := range nodes {
+			n.weight = medianValue(neighbours[n])
+			if n.weight >= 0 {
+				movable = append(movable, n)
+			}
 		}
-		if len(P) == 2 {
-			return float64(P[0]+P[1]) / 2
-		} else {
-			left := float64(P[mid-1] - P[0])
-			right := float64(P[len(P)-1] - P[mid])
-			return (float64(P[mid-1])*right + float64(P[mid])*left) / (left + right)
+		sort.Stable(movable)
+		for i, n := range nodes {
+			if n.weight >= 0 {
+				nodes[i], movable = movable[0], movable[1:]
+			}
 		}
+		nodes.renumber()
 	}
+}
 
-	if iter%2 == 0 {
-		for rank, nodes := range orders {
-			for _, n := range nodes {
-				n.weight = medianValue(n, rank-1)
+// number of crossings of edges of two nodes with neighbouring rank, if first is left of second
+func pairCrossings(first, second []*node) int {
+	count := 0
+	for _, a := range first {
+		for _, b := range second {
+			if a.order > b.order {
+				count++
 			}
-			sort.Sort(nodes)
-		}
-	} else {
-		for rank, nodes := range orders {
-			defer func() {
-				for _, n := range nodes {
-					n.weight = medianValue(n, rank+1)
-				}
-				sort.Sort(nodes)
-			}()
 		}
 	}
+	return count
 }
 
-func transpose(g *graph, orders orders) {
+// exchanges neighbouring nodes as long as it reduces crossings
+// only ranks next to those changed in previous pass are tried again
+func transpose(adj adjacency, orders orders) {
+	ranks := orders.ranks()
+	candidate := make([]bool, len(ranks))
+	for i := range candidate {
+		candidate[i] = true
+	}
+	// neighbours are looked up once, swapped along with nodes
+	uppers := make([][][]*node, len(ranks))
+	lowers := make([][][]*node, len(ranks))
+	for r, rank := range ranks {
+		nodes := orders[rank]
+		uppers[r] = make([][]*node, len(nodes))
+		lowers[r] = make([][]*node, len(nodes))
+		for i, n := range nodes {
+			uppers[r][i], lowers[r][i] = adj.upper[n], adj.lower[n]
+		}
+	}
+
 	improved := true
-	for improved {
+	for iter := 0; improved && iter < MAX_ORDERING_ITER; iter++ {
 		improved = false
-		for rank, nodes := range orders {
-			crossing := func(nodes order) int {
-				if rank+1 == len(orders) { // last rank
-					return 0
-				}
-				count := countCrossings(g, nodes, orders[rank+1])
-				return count
+		for r, rank := range ranks {
+			if !candidate[r] {
+				continue
 			}
-			for i := 0; i < len(nodes)-2; i++ {
-				n1 := nodes[i]
-				n2 := nodes[i+1]
-				exchNodes := make(order, len(nodes))
-				copy(exchNodes, nodes)
-				for i := 0; i < len(exchNodes)-2; i++ {
-					if exchNodes[i] == n1 && exchNodes[i+1] == n2 {
-						exchNodes[i], exchNodes[i+1] = n2, n1
-					}
+			candidate[r] = false
+			nodes, upper, lower := orders[rank], uppers[r], lowers[r]
+			for i := 0; i < len(nodes)-1; i++ {
+				before := pairCrossings(upper[i], upper[i+1]) + pairCrossings(lower[i], lower[i+1])
+				if before == 0 {
+					continue
 				}
-				if crossing(exchNodes) < crossing(nodes) {
+				after := pairCrossings(upper[i+1], upper[i]) + pairCrossings(lower[i+1], lower[i])
+				if after < before {
+					v, w := nodes[i], nodes[i+1]
+					nodes[i], nodes[i+1] = w, v
+					upper[i], upper[i+1] = upper[i+1], upper[i]
+					lower[i], lower[i+1] = lower[i+1], lower[i]
+					w.order, v.order = i, i+1
 					improved = true
-					orders[rank] = exchNodes // use exchanged
+					for _, near := range []int{r - 1, r, r + 1} {
+						if near >= 0 && near < len(ranks) {
+							candidate[near] = true
+						}
+					}
 				}
 			}
 		}
@@ -149,8 +225,8 @@ func transpose(g *graph, orders orders) {
 }
 
 // counts crossings between two ranks
-// algorithm by Barth, Mutzel and Junger 
-func countCrossings(g *graph, northRank, southRank order) int {
+// algorithm by Barth, Mutzel and Junger
+func countCrossings(adj adjacency, northRank, southRank order) int {
 	firstIndex := 1
 	for firstIndex < len(southRank) { // 10
 		firstIndex *= 2 // 1 2 4 8 16
@@ -166,29 +242,24 @@ func countCrossings(g *graph, northRank, southRank order) int {
 	}
 
 	count := 0
+	southOrders := []int{}
 	for _, nn := range northRank {
-		for _, e := range nn.outEdges(g.edges) {
-			if i, ok := orderOfSn[e.to]; ok {
-				index := i + firstIndex
-				for index > 0 {
-					if index%2 == 1 {
-						count += tree[index+1]
-					}
-					index = (index - 1) / 2
-					tree[index]++
-				}
+		// edges have to be inserted sorted by south end too
+		southOrders = southOrders[:0]
+		for _, sn := range adj.lower[nn] {
+			if i, ok := orderOfSn[sn]; ok {
+				southOrders = append(southOrders, i)
 			}
 		}
-		for _, e := range nn.inEdges(g.edges) {
-			if i, ok := orderOfSn[e.from]; ok {
-				index := i + firstIndex
-				for index > 0 {
-					if index%2 == 1 {
-						count += tree[index+1]
-					}
-					index = (index - 1) / 2
-					tree[index]++
+		sort.Ints(southOrders)
+		for _, i := range southOrders {
+			index := i + firstIndex
+			for index > 0 {
+				if index%2 == 1 {
+					count += tree[index+1]
 				}
+				index = (index - 1) / 2
+				tree[index]++
 			}
 		}
 	}
@@ -197,10 +268,11 @@ func countCrossings(g *graph, northRank, southRank order) int {
 }
 
 // counts all crossings in graph
-func crossings(g *graph, orders orders) int {
+func crossings(adj adjacency, orders orders) int {
 	count := 0
-	for rank := 0; rank < len(orders)-2; rank++ {
-		count += countCrossings(g, orders[rank], orders[rank+1])
+	ranks := orders.ranks()
+	for i := 0; i < len(ranks)-1; i++ {
+		count += countCrossings(adj, orders[ranks[i]], orders[ranks[i+1]])
 	}
 	return count
 }
@@ -214,14 +286,15 @@ func initOrder(g *graph) orders {
 		return !visited[n]
 	}
 	asignOrder := func(n *node) {
-		if _, ok := ordersByRank[n.rank]; !ok {
-			ordersByRank[n.rank] = []*node{}
-		}
 		ordersByRank[n.rank] = append(ordersByRank[n.rank], n)
 		visited[n] = true
 	}
+	successors := g.successors()
 	for _, n := range g.nodes {
-		dfs(*g, n, notVisited, asignOrder, nil)
+		dfsFrom(successors, n, notVisited, asignOrder, nil)
+	}
+	for _, nodes := range ordersByRank {
+		nodes.renumber()
 	}
 	return ordersByRank
 }
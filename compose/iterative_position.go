@@ -0,0 +1,421 @@
+package compose
+
+import (
+	"math"
+	"sort"
+
+	"git.yo2.cz/drahoslav/penego/draw"
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+const (
+	rankGap  = 42.0 // space between nodes of neighbouring ranks
+	orderGap = 42.0 // space between nodes within one rank
+)
+
+// size of node along rank (width) and across it (height)
+func (n *node) size() (width, height float64) {
+	return size(n.Composable)
+}
+
+// minimal distance between centers of two neighbouring nodes in one rank
+func separation(a, b *node) float64 {
+	_, ha := a.size()
+	_, hb := b.size()
+	return (ha+hb)/2 + orderGap
+}
+
+// layering of graph used for coordinate assignment
+type layers struct {
+	ranks []order
+	pos   map[*node]int     // index of node in its rank
+	upper map[*node][]*node // neighbours in previous rank, ordered
+	lower map[*node][]*node // neighbours in next rank, ordered
+}
+
+func newLayers(g *graph) layers {
+	l := layers{
+		pos:   map[*node]int{},
+		upper: map[*node][]*node{},
+		lower: map[*node][]*node{},
+	}
+	minRank, maxRank := maxInt, 0
+	for _, n := range g.nodes {
+		if n.rank < minRank {
+			minRank = n.rank
+		}
+		if n.rank > maxRank {
+			maxRank = n.rank
+		}
+	}
+	if len(g.nodes) == 0 {
+		return l
+	}
+	l.ranks = make([]order, maxRank-minRank+1)
+	for _, n := range g.nodes {
+		l.ranks[n.rank-minRank] = append(l.ranks[n.rank-minRank], n)
+	}
+	for _, nodes := range l.ranks {
+		sort.SliceStable(nodes, func(i, j int) bool {
+			return nodes[i].order < nodes[j].order
+		})
+		for i, n := range nodes {
+			l.pos[n] = i
+		}
+	}
+	adj := newAdjacency(g)
+	l.upper, l.lower = adj.upper, adj.lower
+	l.sortNeighbours()
+	return l
+}
+
+func (l layers) sortNeighbours() {
+	for _, neighbours := range []map[*node][]*node{l.upper, l.lower} {
+		for _, nodes := range neighbours {
+			sort.SliceStable(nodes, func(i, j int) bool {
+				return l.pos[nodes[i]] < l.pos[nodes[j]]
+			})
+		}
+	}
+}
+
+// returns layers in opposite direction
+// vertical flips order of ranks, horizontal flips order of nodes within ranks
+func (l layers) flipped(vertical, horizontal bool) layers {
+	f := layers{
+		ranks: make([]order, len(l.ranks)),
+		pos:   map[*node]int{},
+		upper: map[*node][]*node{},
+		lower: map[*node][]*node{},
+	}
+	for i, nodes := range l.ranks {
+		if vertical {
+			i = len(l.ranks) - 1 - i
+		}
+		f.ranks[i] = make(order, len(nodes))
+		for j, n := range nodes {
+			if horizontal {
+				j = len(nodes) - 1 - j
+			}
+			f.ranks[i][j] = n
+			f.pos[n] = j
+		}
+	}
+	for n, nodes := range l.upper {
+		if vertical {
+			f.lower[n] = append([]*node{}, nodes...)
+		} else {
+			f.upper[n] = append([]*node{}, nodes...)
+		}
+	}
+	for n, nodes := range l.lower {
+		if vertical {
+			f.upper[n] = append([]*node{}, nodes...)
+		} else {
+			f.lower[n] = append([]*node{}, nodes...)
+		}
+	}
+	f.sortNeighbours()
+	return f
+}
+
+// marks type 1 conflicts - non-inner segments crossing inner segments (those between two path nodes)
+// inner segments are preferred in alignment, so long edges are drawn straight
+func (l layers) conflicts() map[[2]*node]bool {
+	marked := map[[2]*node]bool{}
+	isInner := func(u, v *node) bool {
+		return u.isPath() && v.isPath()
+	}
+	for i := 1; i < len(l.ranks)-1; i++ {
+		upperRank, rank := l.ranks[i], l.ranks[i+1]
+		k0, scanned := 0, 0
+		for l1, v := range rank {
+			var innerUpper *node
+			for _, u := range l.upper[v] {
+				if isInner(u, v) {
+					innerUpper = u
+				}
+			}
+			if l1 == len(rank)-1 || innerUpper != nil {
+				k1 := len(upperRank) - 1
+				if innerUpper != nil {
+					k1 = l.pos[innerUpper]
+				}
+				for ; scanned <= l1; scanned++ {
+					w := rank[scanned]
+					for _, u := range l.upper[w] {
+						if k := l.pos[u]; (k < k0 || k > k1) && !isInner(u, w) {
+							marked[[2]*node{u, w}] = true
+						}
+					}
+				}
+				k0 = k1
+			}
+		}
+	}
+	return marked
+}
+
+// aligns each node with median of its upper neighbours into blocks
+func (l layers) align(marked map[[2]*node]bool) (root, align map[*node]*node) {
+	root = map[*node]*node{}
+	align = map[*node]*node{}
+	for _, nodes := range l.ranks {
+		for _, n := range nodes {
+			root[n] = n
+			align[n] = n
+		}
+	}
+	for i := 1; i < len(l.ranks); i++ {
+		r := -1
+		for _, v := range l.ranks[i] {
+			upper := l.upper[v]
+			d := len(upper)
+			if d == 0 {
+				continue
+			}
+			for _, m := range []int{(d - 1) / 2, d / 2} {
+				if align[v] != v {
+					break
+				}
+				u := upper[m]
+				isMarked := marked[[2]*node{u, v}] || marked[[2]*node{v, u}]
+				if !isMarked && r < l.pos[u] {
+					align[u] = v
+					root[v] = root[u]
+					align[v] = root[v]
+					r = l.pos[u]
+				}
+			}
+		}
+	}
+	return
+}
+
+// places blocks as close to each other as separation allows
+func (l layers) compact(root, align map[*node]*node) map[*node]float64 {
+	sink := map[*node]*node{}
+	shift := map[*node]float64{}
+	x := map[*node]float64{}
+	placed := map[*node]bool{}
+	pred := map[*node]*node{}
+	for _, nodes := range l.ranks {
+		for i, n := range nodes {
+			sink[n] = n
+			shift[n] = math.Inf(+1)
+			if i > 0 {
+				pred[n] = nodes[i-1]
+			}
+		}
+	}
+
+	var placeBlock func(v *node)
+	placeBlock = func(v *node) {
+		if placed[v] {
+			return
+		}
+		placed[v] = true
+		x[v] = 0
+		w := v
+		for {
+			if p, ok := pred[w]; ok {
+				u := root[p]
+				placeBlock(u)
+				if sink[v] == v {
+					sink[v] = sink[u]
+				}
+				if sink[v] != sink[u] {
+					shift[sink[u]] = math.Min(shift[sink[u]], x[v]-x[u]-separation(p, w))
+				} else {
+					x[v] = math.Max(x[v], x[u]+separation(p, w))
+				}
+			}
+			w = align[w]
+			if w == v {
+				break
+			}
+		}
+	}
+
+	for _, nodes := range l.ranks {
+		for _, n := range nodes {
+			if root[n] == n {
+				placeBlock(n)
+			}
+		}
+	}
+	coords := map[*node]float64{}
+	for _, nodes := range l.ranks {
+		for _, n := range nodes {
+			coords[n] = x[root[n]]
+			if s := shift[sink[root[n]]]; !math.IsInf(s, +1) {
+				coords[n] += s
+			}
+		}
+	}
+	return coords
+}
+
+// assigns coordinates across ranks using algorithm by Brandes and Köpf
+// (Fast and Simple Horizontal Coordinate Assignment)
+// four alignments (to upper/lower and left/right neighbours) are combined by median
+func (l layers) coordinates() map[*node]float64 {
+	marked := l.conflicts()
+	results := []map[*node]float64{}
+	widths := []float64{}
+	for _, vertical := range []bool{false, true} {
+		for _, horizontal := range []bool{false, true} {
+			f := l.flipped(vertical, horizontal)
+			root, align := f.align(marked)
+			coords := f.compact(root, align)
+			min, max := math.Inf(+1), math.Inf(-1)
+			for n, c := range coords {
+				if horizontal {
+					c = -c
+					coords[n] = c
+				}
+				min, max = math.Min(min, c), math.Max(max, c)
+			}
+			results = append(results, coords)
+			widths = append(widths, max-min)
+		}
+	}
+
+	// align to the narrowest one
+	narrowest := 0
+	for i, width := range widths {
+		if width < widths[narrowest] {
+			narrowest = i
+		}
+	}
+	bounds := func(coords map[*node]float64) (min, max float64) {
+		min, max = math.Inf(+1), math.Inf(-1)
+		for _, c := range coords {
+			min, max = math.Min(min, c), math.Max(max, c)
+		}
+		return
+	}
+	targetMin, targetMax := bounds(results[narrowest])
+	for i, coords := range results {
+		min, max := bounds(coords)
+		delta := targetMin - min
+		if i%2 == 1 { // right alignments
+			delta = targetMax - max
+		}
+		for n := range coords {
+			coords[n] += delta
+		}
+	}
+
+	final := map[*node]float64{}
+	for n := range results[0] {
+		cs := []float64{}
+		for _, coords := range results {
+			cs = append(cs, coords[n])
+		}
+		sort.Float64s(cs)
+		final[n] = (cs[1] + cs[2]) / 2
+	}
+
+	// snap to grid and make sure nothing overlaps
+	for _, nodes := range l.ranks {
+		for i, n := range nodes {
+			final[n] = 15 * math.Floor(final[n]/15+0.5)
+			if i > 0 {
+				if min := final[nodes[i-1]] + separation(nodes[i-1], n); final[n] < min {
+					final[n] = 15 * math.Ceil(min/15)
+				}
+			}
+		}
+	}
+	return final
+}
+
+// assigns positions to nodes
+// ranks are placed from left to right, with space based on widest node of each
+// order within rank determines vertical position
+func positions(g *graph) Composition {
+	comp := New()
+	l := newLayers(g)
+	ys := l.coordinates()
+
+	x := 0.0
+	prevWidth := 0.0
+	for i, nodes := range l.ranks {
+		width := 0.0
+		for _, n := range nodes {
+			w, _ := n.size()
+			width = math.Max(width, w)
+		}
+		if i > 0 {
+			x += 15 * math.Ceil(((prevWidth+width)/2+rankGap)/15)
+		}
+		prevWidth = width
+
+		for _, n := range nodes {
+			pos := draw.Pos{x, ys[n]}
+			switch node := n.Composable.(type) {
+			case *net.Transition:
+				comp.transitions[node] = pos
+			case *net.Place:
+				comp.places[node] = pos
+			case *path:
+				if _, ok := comp.pathes[node]; !ok {
+					comp.pathes[node] = []draw.Pos{}
+				}
+				comp.pathes[node] = append(comp.pathes[node], pos)
+			}
+		}
+	}
+	// ranks were walked from left, so reverted paths have to be reversed
+	for path, poss := range comp.pathes {
+		from, _ := comp.Position(path.from)
+		if from.X > poss[0].X {
+			for i, j := 0, len(poss)-1; i < j; i, j = i+1, j-1 {
+				poss[i], poss[j] = poss[j], poss[i]
+			}
+		}
+	}
+	comp.CenterTo(0, 0)
+
+	return comp
+}
+
+// routes long arcs orthogonally
+// bends are put in the middle of space between ranks and redundant ones are removed
+func routeArcs(comp Composition) {
+	// paths are collected first, because setting their positions replaces them in map
+	pathes := make([]*path, 0, len(comp.pathes))
+	for path := range comp.pathes {
+		pathes = append(pathes, path)
+	}
+	for _, path := range pathes {
+		poss := comp.pathes[path]
+		from, _ := comp.Position(path.from)
+		to, _ := comp.Position(path.to)
+		points := append(append([]draw.Pos{from}, poss...), to)
+
+		routed := []draw.Pos{from}
+		for i := 1; i < len(points); i++ {
+			a, b := points[i-1], points[i]
+			if a.Y != b.Y {
+				mx := 15 * math.Floor((a.X+b.X)/30+0.5)
+				routed = append(routed, draw.Pos{mx, a.Y}, draw.Pos{mx, b.Y})
+			}
+			routed = append(routed, b)
+		}
+
+		// remove points lying on line between their neighbours
+		simplified := []draw.Pos{}
+		last := from
+		for i := 1; i < len(routed)-1; i++ {
+			b, c := routed[i], routed[i+1]
+			if (last.X == b.X && b.X == c.X) || (last.Y == b.Y && b.Y == c.Y) || b == c {
+				continue
+			}
+			simplified = append(simplified, b)
+			last = b
+		}
+		comp.SetPathPositions(path.from, path.to, simplified)
+	}
+}
@@ -49,6 +49,9 @@ func (tr *tree) dfsCutval(n *node, parent *edge) {
 }
 
 func rank(g *graph) {
+	if len(g.edges) == 0 {
+		return // single node has rank 0
+	}
 	balance := func(tr *tree) {
 		for _, e := range tr.edges {
 			if e.cutValue == 0 {
@@ -1,9 +1,11 @@
 package compose
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
-	// "git.yo2.cz/drahoslav/penego/draw"
+	"git.yo2.cz/drahoslav/penego/draw"
 	"git.yo2.cz/drahoslav/penego/net"
 )
 
@@ -195,3 +197,224 @@ func TestIterative(test *testing.T) {
 
 	test.Log(comp)
 }
+
+func TestIterativeIncremental(test *testing.T) {
+	network := getNet()
+	previous := New()
+	pinnedPos := map[string]draw.Pos{}
+	for i, place := range network.Places() {
+		if place.Id != "i" {
+			previous.places[place] = draw.Pos{float64(i) * 90, 0}
+			pinnedPos[place.Id] = previous.places[place]
+		}
+	}
+	for i, tran := range network.Transitions() {
+		previous.transitions[tran] = draw.Pos{float64(i) * 90, 180}
+		pinnedPos[tran.Id] = previous.transitions[tran]
+	}
+
+	// net is parsed again, so nodes are matched by id only
+	reloaded := getNet()
+	comp := GetIterativeIncremental(reloaded, previous)
+	for _, place := range reloaded.Places() {
+		pos, ok := comp.Position(place)
+		if !ok {
+			test.Errorf("place %s should be placed", place.Id)
+		}
+		pinned, isPinned := pinnedPos[place.Id]
+		if isPinned && pos != pinned {
+			test.Errorf("place %s should keep position %v, not %v", place.Id, pinned, pos)
+		}
+		if !isPinned {
+			for _, other := range pinnedPos {
+				if other == pos {
+					test.Errorf("new place %s should not overlap other node at %v", place.Id, pos)
+				}
+			}
+		}
+	}
+	for _, tran := range reloaded.Transitions() {
+		if pos, _ := comp.Position(tran); pos != pinnedPos[tran.Id] {
+			test.Errorf("transition %s should keep position %v, not %v", tran.Id, pinnedPos[tran.Id], pos)
+		}
+	}
+}
+
+func TestIterativePositions(test *testing.T) {
+	network := getNet()
+	comp := GetIterative(network)
+
+	type box struct {
+		id   string
+		pos  draw.Pos
+		h, w float64
+	}
+	boxes := []box{}
+	for _, place := range network.Places() {
+		pos, _ := comp.Position(place)
+		boxes = append(boxes, box{place.Id, pos, 2 * draw.PLACE_RADIUS, 2 * draw.PLACE_RADIUS})
+	}
+	for _, tran := range network.Transitions() {
+		pos, _ := comp.Position(tran)
+		boxes = append(boxes, box{tran.Id, pos, draw.TRANSITION_HEIGHT, draw.TRANSITION_WIDTH})
+	}
+	for i, a := range boxes {
+		if snap(a.pos.X, a.pos.Y, 15) != a.pos {
+			test.Errorf("%s should be on grid, but is at %v", a.id, a.pos)
+		}
+		for _, b := range boxes[i+1:] {
+			dx, dy := a.pos.X-b.pos.X, a.pos.Y-b.pos.Y
+			if dx < 0 {
+				dx = -dx
+			}
+			if dy < 0 {
+				dy = -dy
+			}
+			if dx < (a.w+b.w)/2 && dy < (a.h+b.h)/2 {
+				test.Errorf("%s at %v overlaps %s at %v", a.id, a.pos, b.id, b.pos)
+			}
+		}
+	}
+}
+
+func TestIterativeLongEdge(test *testing.T) {
+	network, _ := net.Parse(`
+		a ()
+		b ()
+		c ()
+		----
+		a -> t1[] -> b
+		b -> t2[] -> c
+		a -> t3[] -> c
+	`)
+	comp := GetIterative(network)
+	a, t3 := network.Places()[0], network.Transitions()[2]
+	c := network.Places()[2]
+	for _, poss := range [][]draw.Pos{comp.PathPositions(a, t3), comp.PathPositions(t3, c)} {
+		for i := 1; i < len(poss); i++ {
+			if poss[i-1].X != poss[i].X && poss[i-1].Y != poss[i].Y {
+				test.Errorf("long arc should be routed orthogonally, got %v", poss)
+			}
+		}
+	}
+}
+
+func TestAlignY(test *testing.T) {
+	comp := New()
+	tran := &net.Transition{}
+	comp.places[&net.Place{}] = draw.Pos{0, 0}
+	comp.places[&net.Place{}] = draw.Pos{0, 90}
+	comp.transitions[tran] = draw.Pos{90, 0}
+
+	comp.AlignY()
+	if pos := comp.transitions[tran]; pos != (draw.Pos{90, 45}) {
+		test.Errorf("transition should be centered to 90;45, not %v", pos)
+	}
+	for _, pos := range comp.places {
+		if pos.Y != 0 && pos.Y != 90 {
+			test.Errorf("places should not move, got %v", pos)
+		}
+	}
+}
+
+// returns net consisting of given number of parts,
+// each part is cycle of 4 places and transitions with one arc leading to the next part
+func getLargeNet(parts int) net.Net {
+	places := net.Places{}
+	transitions := net.Transitions{}
+	arc := func(place *net.Place) *net.Arc {
+		return &net.Arc{1, net.NormalArc, place}
+	}
+	for i := 0; i < parts; i++ {
+		part := net.Places{}
+		for j := 0; j < 4; j++ {
+			part = append(part, &net.Place{Id: fmt.Sprintf("p%d_%d", i, j)})
+		}
+		for j := 0; j < 4; j++ {
+			tran := &net.Transition{
+				Id:      fmt.Sprintf("t%d_%d", i, j),
+				Origins: net.Arcs{arc(part[j])},
+				Targets: net.Arcs{arc(part[(j+1)%4])},
+			}
+			if j == 2 && i > 0 {
+				tran.Origins = append(tran.Origins, arc(places[len(places)-1]))
+			}
+			transitions = append(transitions, tran)
+		}
+		places = append(places, part...)
+	}
+	return net.New(places, transitions)
+}
+
+func TestConnectedComponents(test *testing.T) {
+	network, _ := net.Parse(`
+		a ()
+		b ()
+		c ()
+		----
+		a -> [] -> b
+		c -> []
+	`)
+	components := loadGraph(network).connectedComponents()
+	if len(components) != 2 || len(components[0].nodes) != 3 || len(components[1].nodes) != 2 {
+		test.Errorf("graph should have two components of 3 and 2 nodes, got %v", components)
+	}
+
+	comp := GetIterative(network)
+	left, top, right, bottom := comp.bounds()
+	if right-left > 500 || bottom-top > 500 {
+		test.Errorf("components should be packed close together, got %v;%v %v;%v", left, top, right, bottom)
+	}
+}
+
+func TestLargeNet(test *testing.T) {
+	if testing.Short() {
+		test.Skip("large net")
+	}
+	network := getLargeNet(250)
+	start := time.Now()
+	GetIterative(network)
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		test.Errorf("composing of 2000 nodes took %v", elapsed)
+	}
+}
+
+// returns graph prepared for ordering
+func getLargeGraph() (*graph, adjacency, orders) {
+	g := loadGraph(getLargeNet(250)).acyclic()
+	rank(&g)
+	fillPathNodes(&g)
+	return &g, newAdjacency(&g), initOrder(&g)
+}
+
+func BenchmarkWeightMedian(bench *testing.B) {
+	_, adj, orders := getLargeGraph()
+	bench.ResetTimer()
+	for i := 0; i < bench.N; i++ {
+		weightMedian(adj, orders, i)
+	}
+}
+
+func BenchmarkTranspose(bench *testing.B) {
+	_, adj, orders := getLargeGraph()
+	bench.ResetTimer()
+	for i := 0; i < bench.N; i++ {
+		transpose(adj, orders.clone())
+	}
+}
+
+func BenchmarkCountCrossings(bench *testing.B) {
+	_, adj, orders := getLargeGraph()
+	bench.ResetTimer()
+	for i := 0; i < bench.N; i++ {
+		crossings(adj, orders)
+	}
+}
+
+func BenchmarkIterative(bench *testing.B) {
+	network := getLargeNet(250)
+	bench.ResetTimer()
+	for i := 0; i < bench.N; i++ {
+		GetIterative(network)
+	}
+}
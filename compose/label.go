@@ -0,0 +1,296 @@
+package compose
+
+import (
+	"math"
+	"sort"
+
+	"git.yo2.cz/drahoslav/penego/draw"
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+// Label is description of place or transition
+// it can be hit, moved and ghost moved, its position is kept relative to its node
+type Label struct {
+	node Composable
+}
+
+const labelGap = 4.0 // space between node and its label
+
+// returns description of place or transition
+func description(node Composable) string {
+	switch node := node.(type) {
+	case *net.Place:
+		return node.Description
+	case *net.Transition:
+		return node.Description
+	}
+	return ""
+}
+
+// returns offset of label from its node
+// labels without offset are placed above their nodes
+func (comp Composition) labelOffset(node Composable) draw.Pos {
+	if offset, ok := comp.labels[node]; ok {
+		return offset
+	}
+	_, height := size(node)
+	_, labelHeight := draw.LabelSize(description(node))
+	return draw.Pos{0, -(height/2 + labelGap + labelHeight/2)}
+}
+
+// SetLabelOffset sets offset of center of label from its node, as if the label was moved there
+func (comp Composition) SetLabelOffset(node Composable, offset draw.Pos) {
+	comp.labels[node] = offset
+}
+
+// returns position of center of label of node placed on nodePos
+func (comp Composition) labelPosition(node Composable, nodePos draw.Pos) draw.Pos {
+	offset := comp.labelOffset(node)
+	return draw.Pos{nodePos.X + offset.X, nodePos.Y + offset.Y}
+}
+
+// box given by its center and size
+type box struct {
+	left, top, right, bottom float64
+}
+
+func newBox(center draw.Pos, width, height float64) box {
+	return box{center.X - width/2, center.Y - height/2, center.X + width/2, center.Y + height/2}
+}
+
+func (b box) contains(x, y float64) bool {
+	return b.left <= x && x <= b.right && b.top <= y && y <= b.bottom
+}
+
+// area of intersection of two boxes
+func (b box) overlap(o box) float64 {
+	width := math.Min(b.right, o.right) - math.Max(b.left, o.left)
+	height := math.Min(b.bottom, o.bottom) - math.Max(b.top, o.top)
+	if width <= 0 || height <= 0 {
+		return 0
+	}
+	return width * height
+}
+
+// length of part of line segment inside box
+// Liang-Barsky clipping
+func (b box) clip(from, to draw.Pos) float64 {
+	dx, dy := to.X-from.X, to.Y-from.Y
+	t0, t1 := 0.0, 1.0
+	for _, edge := range [][2]float64{
+		{-dx, from.X - b.left},
+		{dx, b.right - from.X},
+		{-dy, from.Y - b.top},
+		{dy, b.bottom - from.Y},
+	} {
+		p, q := edge[0], edge[1]
+		if p == 0 {
+			if q < 0 {
+				return 0
+			}
+			continue
+		}
+		t := q / p
+		if p < 0 {
+			t0 = math.Max(t0, t)
+		} else {
+			t1 = math.Min(t1, t)
+		}
+	}
+	if t0 >= t1 {
+		return 0
+	}
+	return (t1 - t0) * math.Hypot(dx, dy)
+}
+
+// returns candidate offsets of label from node, in order of preference
+func labelCandidates(node Composable, text string) []draw.Pos {
+	width, height := size(node)
+	labelWidth, labelHeight := draw.LabelSize(text)
+	dx := width/2 + labelGap + labelWidth/2
+	dy := height/2 + labelGap + labelHeight/2
+	return []draw.Pos{
+		{0, -dy},   // above
+		{0, dy},    // below
+		{dx, 0},    // right
+		{-dx, 0},   // left
+		{dx, -dy},  // above right
+		{-dx, -dy}, // above left
+		{dx, dy},   // below right
+		{-dx, dy},  // below left
+	}
+}
+
+// PlaceLabels chooses positions of labels which does not have one yet
+// each label is put on the side of its node, where it overlaps the least with nodes, arcs and other labels
+func (comp Composition) PlaceLabels() {
+	nodes := []Composable{}
+	for place := range comp.places {
+		nodes = append(nodes, place)
+	}
+	for tran := range comp.transitions {
+		nodes = append(nodes, tran)
+	}
+	// go from top left, so result does not depend on order of maps
+	sort.SliceStable(nodes, func(i, j int) bool {
+		a, _ := comp.Position(nodes[i])
+		b, _ := comp.Position(nodes[j])
+		if a.Y != b.Y {
+			return a.Y < b.Y
+		}
+		if a.X != b.X {
+			return a.X < b.X
+		}
+		return id(nodes[i]) < id(nodes[j])
+	})
+
+	// obstacles
+	boxes := []box{}
+	for _, node := range nodes {
+		pos, _ := comp.Position(node)
+		width, height := size(node)
+		boxes = append(boxes, newBox(pos, width, height))
+		if tran, ok := node.(*net.Transition); ok && tran.TimeFunc.String() != "" {
+			// attributes are drawn under transition
+			width, height := draw.LabelSize(tran.TimeFunc.String())
+			boxes = append(boxes, newBox(draw.Pos{pos.X, pos.Y + draw.TRANSITION_HEIGHT/2 + 20 - height/2}, width, height))
+		}
+	}
+	segments := [][2]draw.Pos{}
+	addArc := func(from, to Composable, weight int) {
+		poss := comp.PathPositions(from, to)
+		for i := 0; i < len(poss)-1; i++ {
+			segments = append(segments, [2]draw.Pos{poss[i], poss[i+1]})
+		}
+		if weight > 1 { // weight is drawn in the middle
+			a, b := poss[len(poss)/2-1], poss[len(poss)/2]
+			boxes = append(boxes, newBox(draw.Pos{(a.X + b.X) / 2, (a.Y+b.Y)/2 - 6}, 24, 24))
+		}
+	}
+	for tran := range comp.transitions {
+		for _, arc := range tran.Origins {
+			if _, ok := comp.places[arc.Place]; ok && !arc.Place.Hidden() {
+				addArc(arc.Place, tran, arc.Weight)
+			}
+		}
+		for _, arc := range tran.Targets {
+			if _, ok := comp.places[arc.Place]; ok && !arc.Place.Hidden() {
+				addArc(tran, arc.Place, arc.Weight)
+			}
+		}
+	}
+	labelBox := func(node Composable, offset draw.Pos) box {
+		pos, _ := comp.Position(node)
+		width, height := draw.LabelSize(description(node))
+		return newBox(draw.Pos{pos.X + offset.X, pos.Y + offset.Y}, width, height)
+	}
+	for _, node := range nodes {
+		if _, ok := comp.labels[node]; ok && description(node) != "" {
+			boxes = append(boxes, labelBox(node, comp.labels[node]))
+		}
+	}
+
+	for _, node := range nodes {
+		text := description(node)
+		if _, ok := comp.labels[node]; ok || text == "" {
+			continue
+		}
+		best, bestCost := draw.Pos{}, math.Inf(+1)
+		for i, offset := range labelCandidates(node, text) {
+			b := labelBox(node, offset)
+			cost := float64(i) // prefer earlier candidates if nothing is in the way
+			for _, o := range boxes {
+				cost += b.overlap(o)
+			}
+			for _, segment := range segments {
+				cost += b.clip(segment[0], segment[1]) * (b.bottom - b.top)
+			}
+			if cost < bestCost {
+				best, bestCost = offset, cost
+			}
+		}
+		comp.labels[node] = best
+		boxes = append(boxes, labelBox(node, best))
+	}
+}
+
+// applies function to offsets of labels, when composition is rotated or mirrored
+// labels are then moved away from their nodes if they would overlap them
+func (comp Composition) transformLabels(f func(draw.Pos) draw.Pos) {
+	for node, offset := range comp.labels {
+		comp.labels[node] = clearOfNode(node, f(offset))
+	}
+}
+
+// moves offset of label away from its node, so it does not overlap the node
+// it is moved along the axis in which it is relatively further from the node
+func clearOfNode(node Composable, offset draw.Pos) draw.Pos {
+	width, height := size(node)
+	labelWidth, labelHeight := draw.LabelSize(description(node))
+	dx := width/2 + labelGap + labelWidth/2
+	dy := height/2 + labelGap + labelHeight/2
+	if math.Abs(offset.X) >= dx || math.Abs(offset.Y) >= dy {
+		return offset
+	}
+	if math.Abs(offset.X)/dx > math.Abs(offset.Y)/dy {
+		offset.X = math.Copysign(dx, offset.X)
+	} else {
+		offset.Y = math.Copysign(dy, offset.Y)
+	}
+	return offset
+}
+
+// ResetLabels places all labels again
+func (comp Composition) ResetLabels() {
+	for node := range comp.labels {
+		delete(comp.labels, node)
+	}
+	comp.PlaceLabels()
+}
+
+// returns label hit by point
+func (comp Composition) hitLabel(x, y float64) (Label, bool) {
+	isHit := func(node Composable, pos draw.Pos) bool {
+		text := description(node)
+		if text == "" {
+			return false
+		}
+		width, height := draw.LabelSize(text)
+		return newBox(comp.labelPosition(node, pos), width, height).contains(x, y)
+	}
+	for place, pos := range comp.places {
+		if isHit(place, pos) {
+			return Label{place}, true
+		}
+	}
+	for tran, pos := range comp.transitions {
+		if isHit(tran, pos) {
+			return Label{tran}, true
+		}
+	}
+	return Label{}, false
+}
+
+// returns description to be drawn along with node
+// it is empty if drawer draws labels apart from nodes
+func nodeDescription(drawer draw.Drawer, node Composable) string {
+	if _, ok := drawer.(draw.LabelDrawer); ok {
+		return ""
+	}
+	return description(node)
+}
+
+// draws label of node, if drawer can draw labels apart from nodes
+// ghost position of label is used if there is one
+func (comp Composition) drawLabel(drawer draw.Drawer, node Composable, nodePos draw.Pos) {
+	text := description(node)
+	labelDrawer, ok := drawer.(draw.LabelDrawer)
+	if !ok || text == "" {
+		return
+	}
+	pos := comp.labelPosition(node, nodePos)
+	if ghostPos, isGhosted := comp.ghosts[Label{node}]; isGhosted {
+		pos = ghostPos
+	}
+	labelDrawer.DrawLabel(pos, text)
+}
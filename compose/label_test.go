@@ -0,0 +1,131 @@
+package compose
+
+import (
+	"strings"
+	"testing"
+
+	"git.yo2.cz/drahoslav/penego/draw"
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+func TestPlaceLabels(test *testing.T) {
+	network, comp := getLineComp(`
+		p () "input"
+		q () "output"
+		----
+		p -> t[] "fire" -> q
+	`)
+	p, q, t := network.Places()[0], network.Places()[1], network.Transitions()[0]
+	// arc goes above q, so its label should not
+	comp.SetPathPositions(t, q, []draw.Pos{{90, -60}, {180, -60}})
+	comp.labels[p] = draw.Pos{0, 40}
+
+	comp.PlaceLabels()
+	if offset := comp.labels[p]; offset != (draw.Pos{0, 40}) {
+		test.Errorf("offset of label set before should be kept, got %v", offset)
+	}
+	if offset := comp.labels[q]; offset.Y < 0 && offset.X == 0 {
+		test.Errorf("label of q should not be placed above it over the arc, got %v", offset)
+	}
+	if offset := comp.labels[t]; offset.Y < 0 && offset.X == 0 {
+		test.Errorf("label of t should not be placed above it over the arc, got %v", offset)
+	}
+
+	// labels do not overlap with nodes
+	for _, node := range []Composable{p, q, t} {
+		pos, _ := comp.Position(node)
+		width, height := draw.LabelSize(description(node))
+		label := newBox(comp.labelPosition(node, pos), width, height)
+		for _, other := range []Composable{p, q, t} {
+			otherPos, _ := comp.Position(other)
+			width, height := size(other)
+			if overlap := label.overlap(newBox(otherPos, width, height)); overlap > 0 {
+				test.Errorf("label of %s overlaps %s", description(node), description(other))
+			}
+		}
+	}
+}
+
+func TestLabelsRotateMirror(test *testing.T) {
+	network, comp := getLineComp(`
+		p () "input"
+		q () "output"
+		----
+		p -> t[2s] "fire" -> q
+	`)
+	p, q, t := network.Places()[0], network.Places()[1], network.Transitions()[0]
+	comp.PlaceLabels()
+
+	// labels do not overlap with nodes nor arcs
+	check := func(operation string) {
+		for _, node := range []Composable{p, q, t} {
+			pos, _ := comp.Position(node)
+			width, height := draw.LabelSize(description(node))
+			label := newBox(comp.labelPosition(node, pos), width, height)
+			for _, other := range []Composable{p, q, t} {
+				otherPos, _ := comp.Position(other)
+				width, height := size(other)
+				if overlap := label.overlap(newBox(otherPos, width, height)); overlap > 0 {
+					test.Errorf("after %s label of %s overlaps %s", operation, description(node), description(other))
+				}
+			}
+			for _, ends := range [][2]Composable{{p, t}, {t, q}} {
+				poss := comp.PathPositions(ends[0], ends[1])
+				for i := 0; i < len(poss)-1; i++ {
+					if label.clip(poss[i], poss[i+1]) > 0 {
+						test.Errorf("after %s label of %s overlaps arc %v", operation, description(node), poss)
+					}
+				}
+			}
+		}
+	}
+	check("placing")
+	comp.Rotate()
+	check("rotation")
+	if offset := comp.labels[p]; offset.X <= 0 || offset.Y != 0 {
+		test.Errorf("label above p should be rotated to its right, got %v", offset)
+	}
+	comp.MirrorHorizontally()
+	check("horizontal mirroring")
+	if offset := comp.labels[p]; offset.X >= 0 {
+		test.Errorf("label of p should be mirrored to its left, got %v", offset)
+	}
+	comp.Rotate()
+	check("second rotation")
+	comp.MirrorVertically()
+	check("vertical mirroring")
+}
+
+func TestLabelMoveStringParse(test *testing.T) {
+	network, _ := net.Parse(`
+		p () "input"
+		----
+		p -> t[]
+	`)
+	p := network.Places()[0]
+	comp := New()
+	comp.Move(p, 30, 30)
+	comp.Move(network.Transitions()[0], 120, 30)
+
+	label := comp.HitTest(30, 30-35)
+	if _, ok := label.(Label); !ok {
+		test.Fatalf("label should be hit, got %v", label)
+	}
+	comp.GhostMove(label, 41, 77)
+	comp.Move(label, 41, 77)
+	if offset := comp.labels[p]; offset != (draw.Pos{11, 47}) {
+		test.Errorf("label should be moved relative to its node, got %v", offset)
+	}
+
+	str := comp.String()
+	if !strings.Contains(str, "p 30;30 11;47\n") {
+		test.Errorf("label offset should be written, got\n%s", str)
+	}
+	parsed := Parse(str, network)
+	if offset := parsed.labels[p]; offset != (draw.Pos{11, 47}) {
+		test.Errorf("label offset should be parsed, got %v", offset)
+	}
+	if parsed.String() != str {
+		test.Errorf("composition should be the same after parsing\n%s\n%s", str, parsed)
+	}
+}
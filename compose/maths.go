@@ -4,6 +4,7 @@ import (
 	"math"
 
 	"git.yo2.cz/drahoslav/penego/draw"
+	"git.yo2.cz/drahoslav/penego/net"
 )
 
 func hitPlace(x, y float64, pos draw.Pos) bool {
@@ -16,6 +17,76 @@ func hitTransition(x, y float64, pos draw.Pos) bool {
 	return math.Abs(pos.X-x) < rv && math.Abs(pos.Y-y) < rh
 }
 
+func hitWaypoint(x, y float64, pos draw.Pos) bool {
+	const r = 8.0
+	return math.Abs(pos.X-x) < r && math.Abs(pos.Y-y) < r
+}
+
+// whether point is close to line segment between a and b
+func hitSegment(x, y float64, a, b draw.Pos) bool {
+	const r = 6.0
+	dx, dy := b.X-a.X, b.Y-a.Y
+	length2 := dx*dx + dy*dy
+	t := 0.0
+	if length2 > 0 {
+		t = math.Max(0, math.Min(1, ((x-a.X)*dx+(y-a.Y)*dy)/length2))
+	}
+	return math.Hypot(a.X+t*dx-x, a.Y+t*dy-y) < r
+}
+
+// returns point on polyline, which is given part of its length from its start
+func pointAlong(path []draw.Pos, part float64) draw.Pos {
+	length := 0.0
+	for i := 0; i < len(path)-1; i++ {
+		length += math.Hypot(path[i+1].X-path[i].X, path[i+1].Y-path[i].Y)
+	}
+	left := length * math.Max(0, math.Min(1, part))
+	for i := 0; i < len(path)-1; i++ {
+		a, b := path[i], path[i+1]
+		segment := math.Hypot(b.X-a.X, b.Y-a.Y)
+		if left <= segment && segment > 0 {
+			t := left / segment
+			return draw.Pos{a.X + t*(b.X-a.X), a.Y + t*(b.Y-a.Y)}
+		}
+		left -= segment
+	}
+	return path[len(path)-1]
+}
+
+// returns first position below pos, which is far enough from all nodes of composition
+// nodes are moved by step, which should be multiple of grid size
+func (comp Composition) freePosition(pos draw.Pos, step float64) draw.Pos {
+	space := step * 2 / 3
+	isFree := func(pos draw.Pos) bool {
+		for _, other := range comp.places {
+			if math.Abs(pos.X-other.X) < space && math.Abs(pos.Y-other.Y) < space {
+				return false
+			}
+		}
+		for _, other := range comp.transitions {
+			if math.Abs(pos.X-other.X) < space && math.Abs(pos.Y-other.Y) < space {
+				return false
+			}
+		}
+		return true
+	}
+	for !isFree(pos) {
+		pos.Y += step
+	}
+	return pos
+}
+
+// size of place or transition, waypoints have none
+func size(node Composable) (width, height float64) {
+	switch node.(type) {
+	case *net.Place:
+		return 2 * draw.PLACE_RADIUS, 2 * draw.PLACE_RADIUS
+	case *net.Transition:
+		return draw.TRANSITION_WIDTH, draw.TRANSITION_HEIGHT
+	}
+	return 0, 0
+}
+
 func snap(x, y, n float64) draw.Pos {
 	return draw.Pos{x - math.Mod(x, n), y - math.Mod(y, n)}
 }
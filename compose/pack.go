@@ -0,0 +1,99 @@
+package compose
+
+import (
+	"math"
+	"sort"
+
+	"git.yo2.cz/drahoslav/penego/draw"
+)
+
+const packGap = 60.0 // space between packed compositions
+
+// returns bounding box of composition including sizes of nodes
+func (comp Composition) bounds() (left, top, right, bottom float64) {
+	left, top = math.Inf(+1), math.Inf(+1)
+	right, bottom = math.Inf(-1), math.Inf(-1)
+	enhance := func(pos draw.Pos, width, height float64) {
+		left = math.Min(left, pos.X-width/2)
+		right = math.Max(right, pos.X+width/2)
+		top = math.Min(top, pos.Y-height/2)
+		bottom = math.Max(bottom, pos.Y+height/2)
+	}
+	for _, pos := range comp.places {
+		enhance(pos, 2*draw.PLACE_RADIUS, 2*draw.PLACE_RADIUS)
+	}
+	for _, pos := range comp.transitions {
+		enhance(pos, draw.TRANSITION_WIDTH, draw.TRANSITION_HEIGHT)
+	}
+	for _, poss := range comp.pathes {
+		for _, pos := range poss {
+			enhance(pos, 0, 0)
+		}
+	}
+	return
+}
+
+// moves all nodes and waypoints of composition
+func (comp Composition) shift(dx, dy float64) {
+	for place, pos := range comp.places {
+		comp.places[place] = draw.Pos{pos.X + dx, pos.Y + dy}
+	}
+	for tran, pos := range comp.transitions {
+		comp.transitions[tran] = draw.Pos{pos.X + dx, pos.Y + dy}
+	}
+	for _, poss := range comp.pathes {
+		for i, pos := range poss {
+			poss[i] = draw.Pos{pos.X + dx, pos.Y + dy}
+		}
+	}
+}
+
+// puts compositions next to each other in rows, so result is roughly square
+// the highest ones go first
+func pack(compositions []Composition) Composition {
+	if len(compositions) == 1 {
+		return compositions[0]
+	}
+	type box struct {
+		comp                     Composition
+		left, top, width, height float64
+	}
+	boxes := make([]box, len(compositions))
+	area, rowWidth := 0.0, 0.0
+	for i, comp := range compositions {
+		left, top, right, bottom := comp.bounds()
+		boxes[i] = box{comp, left, top, right - left, bottom - top}
+		area += (right - left + packGap) * (bottom - top + packGap)
+		rowWidth = math.Max(rowWidth, right-left)
+	}
+	rowWidth = math.Max(rowWidth, math.Sqrt(area))
+	sort.SliceStable(boxes, func(i, j int) bool {
+		return boxes[i].height > boxes[j].height
+	})
+
+	packed := New()
+	x, y, rowHeight := 0.0, 0.0, 0.0
+	for _, box := range boxes {
+		if x > 0 && x+box.width > rowWidth {
+			x, y = 0, y+rowHeight+packGap
+			rowHeight = 0
+		}
+		// shift by multiple of grid, so nodes stay on it
+		dx := 15 * math.Floor((x-box.left)/15+0.5)
+		dy := 15 * math.Floor((y-box.top)/15+0.5)
+		box.comp.shift(dx, dy)
+		for place, pos := range box.comp.places {
+			packed.places[place] = pos
+		}
+		for tran, pos := range box.comp.transitions {
+			packed.transitions[tran] = pos
+		}
+		for path, poss := range box.comp.pathes {
+			packed.pathes[path] = poss
+		}
+		x += box.width + packGap
+		rowHeight = math.Max(rowHeight, box.height)
+	}
+	packed.CenterTo(0, 0)
+	return packed
+}
@@ -36,28 +36,86 @@ type Drawer interface {
 	SetStyle(style Style)
 }
 
-type Style int
+// LabelDrawer is implemented by drawers which can draw descriptions of places and transitions apart from them
+// nodes are then drawn without description and label is drawn on position chosen by composition
+type LabelDrawer interface {
+	DrawLabel(pos Pos, text string)
+}
 
-const (
-	DefaultStyle = Style(iota)
-	HighlightedStyle
-	FadedStyle
+// StateDrawer is implemented by drawers which can show state of transitions during simulation
+// progress is part of time elapsed till the next scheduled event of transition, negative if there is none
+type StateDrawer interface {
+	DrawTransitionState(pos Pos, enabled bool, progress float64)
+}
+
+// TokenDrawer is implemented by drawers which can draw tokens moving along arcs
+type TokenDrawer interface {
+	DrawToken(pos Pos, n int)
+}
+
+// NodeDrawer is implemented by drawers which refer to nodes (places and transitions) instead of their positions
+// it is told which node is drawn next (to is nil then), or which nodes arc drawn next leads from and to
+type NodeDrawer interface {
+	SetNodes(from, to interface{})
+}
+
+// Style determines colours of drawn element
+// colours are taken from current theme, unless element has its own
+type Style struct {
+	state int
+	color color.RGBA // own colour of element, used if not transparent
+	fill  color.RGBA // own fill of element, used if not transparent
+}
+
+var (
+	DefaultStyle     = Style{state: 0}
+	HighlightedStyle = Style{state: 1}
+	FadedStyle       = Style{state: 2}
 )
 
+// WithColors returns style using given colours instead of theme ones
+// transparent colours are ignored
+func (s Style) WithColors(color, fill color.RGBA) Style {
+	if color.A != 0 {
+		s.color = color
+	}
+	if fill.A != 0 {
+		s.fill = fill
+	}
+	return s
+}
+
+// Colors returns own colours of element, transparent if theme ones are used
+func (s Style) Colors() (color, fill color.RGBA) {
+	return s.color, s.fill
+}
+
 func (s Style) Color() color.RGBA {
-	return map[Style]color.RGBA{
-		DefaultStyle:     BLACKISH,
-		HighlightedStyle: BLACK,
-		FadedStyle:       opaque(DARK_GRAY, 0.5),
-	}[s]
+	theme := CurrentTheme
+	switch s.state {
+	case HighlightedStyle.state:
+		return theme.Highlighted
+	case FadedStyle.state:
+		if s.color.A != 0 {
+			return opaque(s.color, float32(theme.Faded.A)/255)
+		}
+		return theme.Faded
+	}
+	if s.color.A != 0 {
+		return s.color
+	}
+	return theme.Foreground
 }
 
 func (s Style) Background() color.RGBA {
-	return map[Style]color.RGBA{
-		DefaultStyle:     WHITISH,
-		HighlightedStyle: WHITISH,
-		FadedStyle:       opaque(WHITISH, 0.0),
-	}[s]
+	background := CurrentTheme.Background
+	if s.fill.A != 0 {
+		background = s.fill
+	}
+	if s.state == FadedStyle.state {
+		return opaque(background, 0.0)
+	}
+	return background
 }
 
 type Pos struct {
@@ -87,8 +145,20 @@ const (
 	PLACE_RADIUS      = 24.0
 	TRANSITION_WIDTH  = 18.0
 	TRANSITION_HEIGHT = 72.0
+
+	// estimated size of text of labels with font size 14, so they can be placed without graphic context
+	LABEL_HEIGHT     = 14.0
+	LABEL_CHAR_WIDTH = 8.0
+
+	MENU_HEIGHT = 42.0 // of menu bars at top and bottom of gui
 )
 
+// LabelSize returns estimated size of box around label text in font of current theme
+func LabelSize(text string) (width, height float64) {
+	scale := CurrentTheme.FontSize / 14
+	return float64(len([]rune(text))) * LABEL_CHAR_WIDTH * scale, LABEL_HEIGHT * scale
+}
+
 var ( // pseudo constants
 	WHITE   = color.RGBA{255, 255, 255, 255} // #ffffff
 	WHITISH = color.RGBA{239, 239, 239, 255} // #efefef
@@ -101,33 +171,78 @@ var ( // pseudo constants
 	BLACK    = color.RGBA{0, 0, 0, 255}    // #000000
 )
 
+// Init sets styles and transformation of context to the current view of gui, including its zoom
+// it can be called again, when the view changes
 func Init(ctx draw2d.GraphicContext, width, height int) {
+	applyTheme(ctx)
+
+	ctx.SetMatrixTransform(draw2d.NewIdentityMatrix())
+	ctx.Translate(float64(width)/2, float64(height)/2)
+	zoom := Zoom()
+	ctx.Scale(zoom, zoom)
+	ctx.Translate(-guiSt.Float("offset.x"), -guiSt.Float("offset.y"))
+}
+
+// Zoom returns current zoom of gui, 1 means no zoom
+func Zoom() float64 {
+	if zoom := guiSt.Float("zoom"); zoom > 0 {
+		return zoom
+	}
+	return 1
+}
+
+// InitAt is same as Init, but net is centered at given point instead of current view of gui
+func InitAt(ctx draw2d.GraphicContext, width, height int, center Pos) {
 	/* create graphic context and set styles */
-	ctx.SetFontData(draw2d.FontData{Name: "goregular"})
-	ctx.SetFontSize(14)
-	ctx.SetFillColor(WHITISH)
-	ctx.SetStrokeColor(BLACKISH)
-	ctx.SetLineWidth(settingsSt.Float("linewidth"))
+	applyTheme(ctx)
 
 	/* translate origin to center */
-	ox, oy := guiSt.Float("offset.x"), guiSt.Float("offset.y")
+	ctx.Translate(-center.X+float64(width)/2, -center.Y+float64(height)/2)
+}
 
-	ctx.Translate(-ox+float64(width)/2, -oy+float64(height)/2)
+// sets font and colours of current theme
+func applyTheme(ctx draw2d.GraphicContext) {
+	ctx.SetFontData(draw2d.FontData{Name: CurrentTheme.Font})
+	ctx.SetFontSize(CurrentTheme.FontSize)
+	ctx.SetFillColor(CurrentTheme.Background)
+	ctx.SetStrokeColor(CurrentTheme.Foreground)
+	ctx.SetLineWidth(settingsSt.Float("linewidth"))
 }
 
+// Clean fills background of whole context initialized by Init, regardless of zoom
 func Clean(ctx draw2d.GraphicContext, width, height int) {
-	defer ctx.SetLineWidth(settingsSt.Float("linewidth"))
+	defer applyTheme(ctx)
 	defer tempContext(ctx)()
-	ox, oy := guiSt.Float("offset.x"), guiSt.Float("offset.y")
-	ctx.Translate(ox, oy)
+	ctx.SetMatrixTransform(draw2d.NewIdentityMatrix())
+
+	ctx.SetFillColor(CurrentTheme.Background)
+	draw2dkit.Rectangle(ctx, 0, 0, float64(width), float64(height))
+	ctx.Fill()
+}
+
+// CleanAt fills background of context initialized by InitAt with the same center
+func CleanAt(ctx draw2d.GraphicContext, width, height int, center Pos) {
+	defer applyTheme(ctx) // theme or line width might have changed since Init
+	defer tempContext(ctx)()
+	ctx.Translate(center.X, center.Y)
 
 	w, h := float64(width), float64(height)
 
 	/* background */
+	ctx.SetFillColor(CurrentTheme.Background)
 	draw2dkit.Rectangle(ctx, -w/2, -h/2, w/2, h/2)
 	ctx.Fill()
 }
 
+// Caption draws text to top left corner of image
+// context must not be translated by Init
+func Caption(ctx draw2d.GraphicContext, text string) {
+	defer tempContext(ctx)()
+	applyTheme(ctx)
+	ctx.SetFillColor(CurrentTheme.Foreground)
+	ctx.FillStringAt(text, 8, 8+CurrentTheme.FontSize)
+}
+
 func ExportBorder(ctx draw2d.GraphicContext) {
 	defer tempContext(ctx)()
 	ox, oy := guiSt.Float("offset.x"), guiSt.Float("offset.y")
@@ -146,19 +261,28 @@ func Splash(ctx draw2d.GraphicContext, title string) {
 	defer tempContext(ctx)()
 	ctx.SetFontData(draw2d.FontData{Name: "gobold"})
 	ctx.SetFontSize(48)
-	ctx.SetFillColor(DARK_GRAY)
+	ctx.SetFillColor(CurrentTheme.Foreground)
 	drawCenteredString(ctx, title, 0, 0)
 }
 
+// SelectionBox draws rectangle of selection being dragged between two corners
+func SelectionBox(ctx draw2d.GraphicContext, from, to Pos) {
+	defer tempContext(ctx)()
+	draw2dkit.Rectangle(ctx, from.X, from.Y, to.X, to.Y)
+	ctx.SetLineWidth(1 / Zoom())
+	ctx.SetStrokeColor(CurrentTheme.Highlighted)
+	ctx.SetFillColor(opaque(CurrentTheme.Highlighted, 0.1))
+	ctx.FillStroke()
+}
+
 func Menu(ctx draw2d.GraphicContext, sWidth, sHeight int, itemsNames []string, activeIndex int, tooltip string, disabled []bool, pos Gravity) ([]float64, float64, float64) {
 	defer tempContext(ctx)()
 
-	ox, oy := guiSt.Float("offset.x"), guiSt.Float("offset.y")
-	ctx.Translate(ox-float64(sWidth)/2, oy-float64(sHeight)/2)
+	ctx.SetMatrixTransform(draw2d.NewIdentityMatrix()) // menu is not zoomed
 
 	const (
 		padding = 16.0
-		height  = 42.0
+		height  = MENU_HEIGHT
 	)
 
 	var widths = make([]float64, len(itemsNames))
@@ -252,24 +376,17 @@ func Place(ctx draw2d.GraphicContext, style Style, pos Pos, n int, description s
 	ctx.FillStroke()
 
 	// tokens
+	dots, dotRadius := tokenDots(n)
 	switch {
-	case n == 1: // draw dot
-		draw2dkit.Circle(ctx, x, y, 6)
-		ctx.SetFillColor(style.Color())
-		ctx.Fill()
-	case 1 < n && n < 6: // draw dots
-		rr := float64(r) / (3 - float64(n)*0.25)
-		for i := 1; i <= n; i++ {
-			angle := math.Pi / float64(n) * float64(i) * 2
-			xx := x + math.Sin(angle)*rr
-			yy := y + math.Cos(angle)*rr
-
-			draw2dkit.Circle(ctx, xx, yy, 5)
+	case n <= 0:
+	case dots != nil: // draw dots
+		for _, dot := range dots {
+			draw2dkit.Circle(ctx, x+dot.X, y+dot.Y, dotRadius)
 		}
 		ctx.SetFillColor(style.Color())
 		ctx.Fill()
 
-	case n >= 6: // draw numbers
+	default: // draw numbers
 		ctx.Save()
 		ctx.SetFontData(draw2d.FontData{Name: "gomono"})
 		ctx.SetFillColor(style.Color())
@@ -299,6 +416,55 @@ func Place(ctx draw2d.GraphicContext, style Style, pos Pos, n int, description s
 
 }
 
+// MaxTokenDots is the greatest number of tokens drawn as dots, more tokens are drawn as number
+var MaxTokenDots = 12
+
+// returns positions of token dots relative to center of place and their radius
+// dots are laid out in concentric rings, nil is returned if n tokens should not be drawn as dots
+func tokenDots(n int) ([]Pos, float64) {
+	if n <= 0 || n > MaxTokenDots {
+		return nil, 0
+	}
+	ring := func(count int, radius float64) []Pos {
+		dots := []Pos{}
+		for i := 1; i <= count; i++ {
+			angle := math.Pi / float64(count) * float64(i) * 2
+			dots = append(dots, Pos{math.Sin(angle) * radius, math.Cos(angle) * radius})
+		}
+		return dots
+	}
+	switch {
+	case n == 1:
+		return []Pos{{0, 0}}, 6
+	case n < 6:
+		return ring(n, PLACE_RADIUS/(3-float64(n)*0.25)), 5
+	}
+
+	dotRadius := 4.0
+	if n > 12 {
+		dotRadius = 3
+	}
+	dots := []Pos{}
+	radius := PLACE_RADIUS - 4 - dotRadius
+	for left := n; left > 0; {
+		if radius < dotRadius+1 { // one in the middle
+			if left > 1 {
+				return nil, 0 // does not fit
+			}
+			return append(dots, Pos{0, 0}), dotRadius
+		}
+		// how many dots fits on ring, so they do not touch
+		count := int(math.Pi / math.Asin((dotRadius+1)/radius))
+		if count > left {
+			count = left
+		}
+		dots = append(dots, ring(count, radius)...)
+		left -= count
+		radius -= 2*dotRadius + 2
+	}
+	return dots, dotRadius
+}
+
 func Transition(ctx draw2d.GraphicContext, style Style, pos Pos, attrs, description string) {
 	w, h := TRANSITION_WIDTH, TRANSITION_HEIGHT
 	x, y := pos.X, pos.Y
@@ -322,6 +488,49 @@ func Transition(ctx draw2d.GraphicContext, style Style, pos Pos, attrs, descript
 	}
 }
 
+// TransitionState draws frame around enabled transition
+// and fills the transition from bottom according to progress of its next event
+func TransitionState(ctx draw2d.GraphicContext, pos Pos, enabled bool, progress float64) {
+	w, h := TRANSITION_WIDTH, TRANSITION_HEIGHT
+	x, y := pos.X, pos.Y
+	defer tempContext(ctx)()
+	active := CurrentTheme.Active
+
+	if progress >= 0 {
+		progress = math.Min(progress, 1)
+		draw2dkit.Rectangle(ctx, x-w/2, y+h/2-h*progress, x+w/2, y+h/2)
+		ctx.SetFillColor(opaque(active, 0.5))
+		ctx.Fill()
+	}
+	if enabled {
+		const gap = 3
+		draw2dkit.Rectangle(ctx, x-w/2-gap, y-h/2-gap, x+w/2+gap, y+h/2+gap)
+		ctx.SetStrokeColor(active)
+		ctx.Stroke()
+	}
+}
+
+// Token draws token travelling along arc, n is number of tokens moving together
+func Token(ctx draw2d.GraphicContext, style Style, pos Pos, n int) {
+	defer tempContext(ctx)()
+	draw2dkit.Circle(ctx, pos.X, pos.Y, 6)
+	ctx.SetFillColor(CurrentTheme.Active)
+	ctx.SetStrokeColor(style.Color())
+	ctx.FillStroke()
+	if n > 1 {
+		ctx.SetFillColor(style.Color())
+		drawCenteredString(ctx, strconv.Itoa(n), pos.X, pos.Y-10)
+	}
+}
+
+// Label draws text centered at pos
+func Label(ctx draw2d.GraphicContext, style Style, pos Pos, text string) {
+	defer tempContext(ctx)()
+	_, top, _, bottom := ctx.GetStringBounds(text)
+	ctx.SetFillColor(style.Color())
+	drawCenteredString(ctx, text, pos.X, pos.Y-(top+bottom)/2)
+}
+
 func Arc(ctx draw2d.GraphicContext, style Style, path []Pos, dir Direction, weight int) {
 	r := PLACE_RADIUS
 	w := TRANSITION_WIDTH
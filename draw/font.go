@@ -27,9 +27,19 @@ func (fc customFontCache) Load(fd draw2d.FontData) (*truetype.Font, error) {
 	return font, nil
 }
 
-func init() {
-	fontCache := customFontCache{}
+var fontCache = customFontCache{}
 
+// parses ttf font and stores it in font cache
+func storeFont(name string, TTF []byte) error {
+	font, err := truetype.Parse(TTF)
+	if err != nil {
+		return err
+	}
+	fontCache.Store(draw2d.FontData{Name: name}, font)
+	return nil
+}
+
+func init() {
 	TTFs := map[string]([]byte){
 		"goregular": goregular.TTF,
 		"gobold":    gobold.TTF,
@@ -39,11 +49,9 @@ func init() {
 	}
 
 	for fontName, TTF := range TTFs {
-		font, err := truetype.Parse(TTF)
-		if err != nil {
+		if err := storeFont(fontName, TTF); err != nil {
 			panic(err)
 		}
-		fontCache.Store(draw2d.FontData{Name: fontName}, font)
 	}
 
 	draw2d.SetFontCache(fontCache)
@@ -0,0 +1,74 @@
+package draw
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dkit"
+)
+
+// LegendDrawer is implemented by drawers which can draw legend of heat colours
+type LegendDrawer interface {
+	DrawLegend(pos Pos, title, min, max string)
+}
+
+const (
+	LEGEND_WIDTH  = 140.0
+	LEGEND_HEIGHT = 56.0
+)
+
+// colours of heat scale, from cold to hot
+var heatScale = []color.RGBA{
+	{255, 255, 204, 255}, // #ffffcc
+	{254, 217, 118, 255}, // #fed976
+	{253, 141, 60, 255},  // #fd8d3c
+	{227, 26, 28, 255},   // #e31a1c
+	{128, 0, 38, 255},    // #800026
+}
+
+// HeatColor returns colour of value between 0 (cold) and 1 (hot)
+func HeatColor(value float64) color.RGBA {
+	if math.IsNaN(value) {
+		value = 0
+	}
+	value = math.Max(0, math.Min(1, value))
+	position := value * float64(len(heatScale)-1)
+	i := int(position)
+	if i >= len(heatScale)-1 {
+		return heatScale[len(heatScale)-1]
+	}
+	a, b, t := heatScale[i], heatScale[i+1], position-float64(i)
+	mix := func(x, y uint8) uint8 {
+		return uint8(math.Round(float64(x) + (float64(y)-float64(x))*t))
+	}
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), 255}
+}
+
+// Legend draws title, bar of heat colours and values of its ends below it
+// pos is top left corner of legend
+func Legend(ctx draw2d.GraphicContext, style Style, pos Pos, title, min, max string) {
+	defer tempContext(ctx)()
+	const steps, barHeight = 20, 12
+	x, y := pos.X, pos.Y
+	w := LEGEND_WIDTH
+
+	ctx.SetFillColor(style.Color())
+	ctx.FillStringAt(title, x, y+LABEL_HEIGHT)
+
+	barTop := y + LABEL_HEIGHT + 6
+	for i := 0; i < steps; i++ {
+		draw2dkit.Rectangle(ctx, x+w*float64(i)/steps, barTop, x+w*float64(i+1)/steps, barTop+barHeight)
+		ctx.SetFillColor(HeatColor((float64(i) + 0.5) / steps))
+		ctx.Fill()
+	}
+	draw2dkit.Rectangle(ctx, x, barTop, x+w, barTop+barHeight)
+	ctx.SetStrokeColor(style.Color())
+	ctx.SetLineWidth(1)
+	ctx.Stroke()
+
+	ctx.SetFillColor(style.Color())
+	ctx.FillStringAt(min, x, barTop+barHeight+LABEL_HEIGHT+2)
+	_, _, right, _ := ctx.GetStringBounds(max)
+	ctx.FillStringAt(max, x+w-right, barTop+barHeight+LABEL_HEIGHT+2)
+}
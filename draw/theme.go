@@ -0,0 +1,162 @@
+package draw
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Theme defines colours and font used to draw nets
+type Theme struct {
+	Foreground  color.RGBA // lines and texts
+	Background  color.RGBA // canvas and inside of nodes
+	Highlighted color.RGBA // lines and texts of highlighted elements
+	Faded       color.RGBA // lines and texts of elements being moved
+	Active      color.RGBA // enabled transitions and progress of their events during simulation
+	Font        string     // name of font in font cache
+	FontSize    float64
+}
+
+var (
+	LightTheme = Theme{
+		Foreground:  BLACKISH,
+		Background:  WHITISH,
+		Highlighted: BLACK,
+		Faded:       opaque(DARK_GRAY, 0.5),
+		Active:      color.RGBA{46, 139, 87, 255}, // #2e8b57
+		Font:        "goregular",
+		FontSize:    14,
+	}
+	DarkTheme = Theme{
+		Foreground:  LIGHT_GRAY,
+		Background:  DARK_GRAY,
+		Highlighted: WHITE,
+		Faded:       opaque(LIGHT_GRAY, 0.5),
+		Active:      color.RGBA{127, 219, 127, 255}, // #7fdb7f
+		Font:        "goregular",
+		FontSize:    14,
+	}
+	HighContrastTheme = Theme{
+		Foreground:  BLACK,
+		Background:  WHITE,
+		Highlighted: color.RGBA{0, 0, 255, 255}, // #0000ff
+		Faded:       opaque(GRAY, 0.7),
+		Active:      color.RGBA{0, 160, 0, 255}, // #00a000
+		Font:        "gobold",
+		FontSize:    16,
+	}
+)
+
+// Themes are predefined themes by name
+var Themes = map[string]Theme{
+	"light":    LightTheme,
+	"dark":     DarkTheme,
+	"contrast": HighContrastTheme,
+}
+
+// CurrentTheme is theme used by all drawing functions
+var CurrentTheme = LightTheme
+
+// LoadTheme returns predefined theme of given name or theme read from file of that name
+func LoadTheme(name string) (Theme, error) {
+	if theme, ok := Themes[name]; ok {
+		return theme, nil
+	}
+	file, err := os.Open(name)
+	if err != nil {
+		return Theme{}, err
+	}
+	defer file.Close()
+	return ReadTheme(file)
+}
+
+// ReadTheme parses theme in format of lines `key value`
+// keys are base (name of predefined theme to start from), foreground, background, highlighted, faded, active, font and fontsize
+// base can be only the first key, so it does not override keys above it
+// colours are written as #rrggbb or #rrggbbaa, font is name of go font or path to ttf file
+// lines starting with // are comments
+func ReadTheme(reader io.Reader) (Theme, error) {
+	theme := LightTheme
+	scanner := bufio.NewScanner(reader)
+	first := true
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		isFirst := first
+		first = false
+		parts := strings.Fields(line)
+		if len(parts) != 2 {
+			return theme, fmt.Errorf("theme line %d: expected `key value`", n)
+		}
+		key, value := strings.ToLower(parts[0]), parts[1]
+		var err error
+		switch key {
+		case "base":
+			base, ok := Themes[value]
+			if !ok {
+				err = fmt.Errorf("unknown theme %s", value)
+			}
+			if !isFirst {
+				err = fmt.Errorf("base has to be the first key")
+			}
+			theme = base
+		case "foreground":
+			theme.Foreground, err = ParseColor(value)
+		case "background":
+			theme.Background, err = ParseColor(value)
+		case "highlighted":
+			theme.Highlighted, err = ParseColor(value)
+		case "faded":
+			theme.Faded, err = ParseColor(value)
+		case "active":
+			theme.Active, err = ParseColor(value)
+		case "font":
+			if _, ok := fontCache[value]; !ok {
+				err = LoadFont(value)
+			}
+			theme.Font = value
+		case "fontsize":
+			theme.FontSize, err = strconv.ParseFloat(value, 64)
+			if err == nil && theme.FontSize <= 0 {
+				err = fmt.Errorf("font size has to be positive")
+			}
+		default:
+			err = fmt.Errorf("unknown key %s", key)
+		}
+		if err != nil {
+			return theme, fmt.Errorf("theme line %d: %s", n, err)
+		}
+	}
+	return theme, scanner.Err()
+}
+
+// LoadFont reads ttf file and stores it in font cache under its path
+func LoadFont(path string) error {
+	ttf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return storeFont(path, ttf)
+}
+
+// ParseColor parses colour written as #rrggbb or #rrggbbaa
+func ParseColor(str string) (color.RGBA, error) {
+	if !strings.HasPrefix(str, "#") || (len(str) != 7 && len(str) != 9) {
+		return color.RGBA{}, fmt.Errorf("invalid colour %s", str)
+	}
+	n, err := strconv.ParseUint(str[1:], 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid colour %s", str)
+	}
+	if len(str) == 7 {
+		n = n<<8 | 0xff
+	}
+	return color.RGBA{uint8(n >> 24), uint8(n >> 16), uint8(n >> 8), uint8(n)}, nil
+}
@@ -0,0 +1,64 @@
+package draw
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestParseColor(test *testing.T) {
+	for str, expected := range map[string]color.RGBA{
+		"#ffcc00":   {255, 204, 0, 255},
+		"#FFCC00":   {255, 204, 0, 255},
+		"#ffcc0080": {255, 204, 0, 128},
+		"#00000000": {0, 0, 0, 0},
+	} {
+		if c, err := ParseColor(str); err != nil || c != expected {
+			test.Errorf("colour %s should be %v, got %v %v", str, expected, c, err)
+		}
+	}
+	for _, str := range []string{"", "ffcc00", "#fc0", "#ffcc0", "#ffcc000", "#ffcc00800", "#ffcc0g", "#+fcc00"} {
+		if _, err := ParseColor(str); err == nil {
+			test.Errorf("colour %s should be invalid", str)
+		}
+	}
+}
+
+func TestReadTheme(test *testing.T) {
+	theme, err := ReadTheme(strings.NewReader(`
+		// comment
+		base dark
+
+		foreground #ffcc00
+		FontSize 12
+	`))
+	if err != nil {
+		test.Fatalf("theme should be read, got %s", err)
+	}
+	if theme.Foreground != (color.RGBA{255, 204, 0, 255}) || theme.FontSize != 12 {
+		test.Errorf("keys should override base theme, got %v", theme)
+	}
+	if theme.Background != DarkTheme.Background || theme.Font != DarkTheme.Font {
+		test.Errorf("other values should be taken from base theme, got %v", theme)
+	}
+
+	theme, err = ReadTheme(strings.NewReader("active #00ff00"))
+	if err != nil || theme.Background != LightTheme.Background || theme.Active != (color.RGBA{0, 255, 0, 255}) {
+		test.Errorf("theme without base should start from light theme, got %v %v", theme, err)
+	}
+
+	for input, expected := range map[string]string{
+		"foreground #ffcc00\nbase dark": "line 2: base has to be the first key",
+		"base sepia":                    "line 1: unknown theme sepia",
+		"foreground #ffcc00\ncolor red": "line 2: unknown key color",
+		"fontsize 0":                    "line 1: font size has to be positive",
+		"fontsize -3":                   "line 1: font size has to be positive",
+		"fontsize big":                  "line 1:",
+		"background":                    "line 1: expected `key value`",
+		"faded #ffcc0":                  "line 1: invalid colour",
+	} {
+		if _, err := ReadTheme(strings.NewReader(input)); err == nil || !strings.Contains(err.Error(), expected) {
+			test.Errorf("theme `%s` should fail with `%s`, got %v", input, expected, err)
+		}
+	}
+}
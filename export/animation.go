@@ -0,0 +1,241 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/color/palette"
+	imgdraw "image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"git.yo2.cz/drahoslav/penego/draw"
+	"github.com/llgcode/draw2d/draw2dimg"
+)
+
+// Animation is sequence of frames saved as GIF, APNG or numbered PNG files
+// frames of PNG sequence are written as they are added,
+// frames of GIF and APNG are kept in memory (paletted or compressed) until Close writes the file
+type Animation struct {
+	filename string
+	format   string
+	count    int
+	gif      gif.GIF
+	apng     []apngFrame
+	header   []byte // IHDR of the first APNG frame
+}
+
+type apngFrame struct {
+	data  []byte // content of IDAT chunks
+	delay time.Duration
+}
+
+// IsAnimation tells whether file should contain animation
+// that is file with .gif or .apng extension, or .png file with % pattern for frame number (e.g. frames/%05d.png)
+func IsAnimation(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".gif", ".apng":
+		return true
+	case ".png":
+		return strings.Contains(filename, "%")
+	}
+	return false
+}
+
+func NewAnimation(filename string) (*Animation, error) {
+	if !IsAnimation(filename) {
+		return nil, fmt.Errorf("Unknown animation format %s", filename)
+	}
+	format := strings.ToLower(filepath.Ext(filename))[1:]
+	if format == "png" {
+		if err := os.MkdirAll(filepath.Dir(fmt.Sprintf(filename, 0)), 0755); err != nil {
+			return nil, err
+		}
+	}
+	return &Animation{filename: filename, format: format}, nil
+}
+
+// Len returns number of frames added so far
+func (anim *Animation) Len() int {
+	return anim.count
+}
+
+// AddFrame adds image with caption in its top left corner, shown for given time
+// image itself is not changed
+func (anim *Animation) AddFrame(img *image.RGBA, caption string, delay time.Duration) error {
+	frame := image.NewRGBA(img.Bounds())
+	copy(frame.Pix, img.Pix)
+	if caption != "" {
+		draw.Caption(draw2dimg.NewGraphicContext(frame), caption)
+	}
+	return anim.add(frame, delay)
+}
+
+// adds frame, it is counted only if it is encoded successfully
+func (anim *Animation) add(img *image.RGBA, delay time.Duration) error {
+	if err := anim.encode(img, delay); err != nil {
+		return err
+	}
+	anim.count++
+	return nil
+}
+
+func (anim *Animation) encode(img *image.RGBA, delay time.Duration) error {
+	switch anim.format {
+	case "png":
+		file, err := os.Create(fmt.Sprintf(anim.filename, anim.count))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		return png.Encode(file, img)
+	case "gif":
+		paletted := image.NewPaletted(img.Bounds(), gifPalette())
+		imgdraw.Draw(paletted, img.Bounds(), img, image.ZP, imgdraw.Src)
+		anim.gif.Image = append(anim.gif.Image, paletted)
+		anim.gif.Delay = append(anim.gif.Delay, int(delay/(10*time.Millisecond)))
+		return nil
+	case "apng":
+		buf := &bytes.Buffer{}
+		if err := png.Encode(buf, img); err != nil {
+			return err
+		}
+		header, data, err := pngData(buf.Bytes())
+		if err != nil {
+			return err
+		}
+		if anim.header == nil {
+			anim.header = header
+		} else if !bytes.Equal(anim.header, header) {
+			return fmt.Errorf("frame %d differs in size or colour type", anim.count)
+		}
+		anim.apng = append(anim.apng, apngFrame{data, delay})
+		return nil
+	}
+	return nil
+}
+
+// Close writes GIF or APNG file, frames of PNG sequence are written already
+func (anim *Animation) Close() error {
+	if anim.format == "png" {
+		return nil
+	}
+	if anim.count == 0 {
+		return fmt.Errorf("animation has no frames")
+	}
+	file, err := os.Create(anim.filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if anim.format == "gif" {
+		return gif.EncodeAll(file, &anim.gif)
+	}
+	return anim.writeApng(file)
+}
+
+// colours of theme followed by web safe colours
+func gifPalette() color.Palette {
+	theme := draw.CurrentTheme
+	colors := color.Palette{theme.Background, theme.Foreground, theme.Highlighted, theme.Faded, theme.Active}
+	return append(colors, palette.WebSafe...)
+}
+
+/* APNG */
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// returns content of IHDR chunk and joined content of IDAT chunks of png file
+func pngData(file []byte) (header, data []byte, err error) {
+	if !bytes.HasPrefix(file, pngSignature) {
+		return nil, nil, fmt.Errorf("not a png")
+	}
+	for rest := file[len(pngSignature):]; len(rest) >= 12; {
+		length := int(binary.BigEndian.Uint32(rest))
+		if len(rest) < 12+length {
+			return nil, nil, fmt.Errorf("truncated png chunk")
+		}
+		kind, content := string(rest[4:8]), rest[8:8+length]
+		switch kind {
+		case "IHDR":
+			header = content
+		case "IDAT":
+			data = append(data, content...)
+		}
+		rest = rest[12+length:]
+	}
+	return header, data, nil
+}
+
+type chunk struct {
+	kind    string
+	content []byte
+}
+
+func writeChunk(w io.Writer, kind string, content []byte) error {
+	buf := make([]byte, 8, 12+len(content))
+	binary.BigEndian.PutUint32(buf, uint32(len(content)))
+	copy(buf[4:], kind)
+	buf = append(buf, content...)
+	buf = buf[:len(buf)+4]
+	binary.BigEndian.PutUint32(buf[len(buf)-4:], crc32.ChecksumIEEE(buf[4:len(buf)-4]))
+	_, err := w.Write(buf)
+	return err
+}
+
+func (anim *Animation) writeApng(w io.Writer) error {
+	be := binary.BigEndian
+	width, height := be.Uint32(anim.header[0:]), be.Uint32(anim.header[4:])
+	chunks := []chunk{{"IHDR", anim.header}}
+
+	actl := make([]byte, 8)
+	be.PutUint32(actl, uint32(len(anim.apng))) // number of frames
+	be.PutUint32(actl[4:], 0)                  // repeat forever
+	chunks = append(chunks, chunk{"acTL", actl})
+
+	sequence := uint32(0)
+	for i, frame := range anim.apng {
+		delay := frame.delay / time.Millisecond
+		if delay > 0xffff {
+			delay = 0xffff
+		}
+		fctl := make([]byte, 26)
+		be.PutUint32(fctl, sequence)
+		be.PutUint32(fctl[4:], width)
+		be.PutUint32(fctl[8:], height)
+		// offset x, y are 0
+		be.PutUint16(fctl[20:], uint16(delay))
+		be.PutUint16(fctl[22:], 1000)
+		// dispose and blend operations are 0 (none, source)
+		sequence++
+		chunks = append(chunks, chunk{"fcTL", fctl})
+
+		if i == 0 { // first frame is also default image
+			chunks = append(chunks, chunk{"IDAT", frame.data})
+			continue
+		}
+		fdat := make([]byte, 4, 4+len(frame.data))
+		be.PutUint32(fdat, sequence)
+		sequence++
+		chunks = append(chunks, chunk{"fdAT", append(fdat, frame.data...)})
+	}
+	chunks = append(chunks, chunk{"IEND", nil})
+
+	if _, err := w.Write(pngSignature); err != nil {
+		return err
+	}
+	for _, c := range chunks {
+		if err := writeChunk(w, c.kind, c.content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
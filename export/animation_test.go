@@ -0,0 +1,139 @@
+package export
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIsAnimation(test *testing.T) {
+	for filename, expected := range map[string]bool{
+		"run.gif":          true,
+		"run.APNG":         true,
+		"frames/%05d.png":  true,
+		"image.png":        false,
+		"image.svg":        false,
+		"frames/%05d.jpeg": false,
+	} {
+		if IsAnimation(filename) != expected {
+			test.Errorf("%s should be animation: %v", filename, expected)
+		}
+	}
+}
+
+// returns frames filled with given colours
+func testFrames(colors ...color.RGBA) []*image.RGBA {
+	frames := []*image.RGBA{}
+	for _, clr := range colors {
+		img := image.NewRGBA(image.Rect(0, 0, 4, 3))
+		for i := 0; i < len(img.Pix); i += 4 {
+			img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = clr.R, clr.G, clr.B, clr.A
+		}
+		frames = append(frames, img)
+	}
+	return frames
+}
+
+func recordTestAnimation(test *testing.T, filename string) {
+	anim, err := NewAnimation(filename)
+	if err != nil {
+		test.Fatal(err)
+	}
+	for _, frame := range testFrames(color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255}) {
+		if err := anim.add(frame, 500*time.Millisecond); err != nil {
+			test.Fatal(err)
+		}
+	}
+	if err := anim.Close(); err != nil {
+		test.Fatal(err)
+	}
+}
+
+func TestAnimationGif(test *testing.T) {
+	dir, _ := ioutil.TempDir("", "penego")
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "run.gif")
+	recordTestAnimation(test, filename)
+
+	file, _ := os.Open(filename)
+	defer file.Close()
+	decoded, err := gif.DecodeAll(file)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if len(decoded.Image) != 2 || decoded.Delay[1] != 50 {
+		test.Errorf("gif should have 2 frames for half of second, got %d %v", len(decoded.Image), decoded.Delay)
+	}
+	if r, _, b, _ := decoded.Image[1].At(0, 0).RGBA(); r != 0 || b != 0xffff {
+		test.Errorf("second frame should be blue")
+	}
+}
+
+func TestAnimationApng(test *testing.T) {
+	dir, _ := ioutil.TempDir("", "penego")
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "run.apng")
+	recordTestAnimation(test, filename)
+
+	// default image is the first frame
+	file, _ := os.Open(filename)
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		test.Fatal(err)
+	}
+	if r, _, b, _ := img.At(0, 0).RGBA(); r != 0xffff || b != 0 {
+		test.Errorf("default image should be red")
+	}
+
+	content, _ := ioutil.ReadFile(filename)
+	kinds := ""
+	sequence := []uint32{}
+	for rest := content[len(pngSignature):]; len(rest) >= 12; {
+		length := binary.BigEndian.Uint32(rest)
+		kind := string(rest[4:8])
+		kinds += kind + " "
+		if kind == "fcTL" || kind == "fdAT" {
+			sequence = append(sequence, binary.BigEndian.Uint32(rest[8:]))
+		}
+		rest = rest[12+length:]
+	}
+	if expected := "IHDR acTL fcTL IDAT fcTL fdAT IEND "; kinds != expected {
+		test.Errorf("apng should consist of chunks\n%s\nbut is\n%s", expected, kinds)
+	}
+	for i, n := range sequence {
+		if n != uint32(i) {
+			test.Errorf("sequence numbers should go from 0, got %v", sequence)
+			break
+		}
+	}
+}
+
+func TestAnimationFrames(test *testing.T) {
+	dir, _ := ioutil.TempDir("", "penego")
+	defer os.RemoveAll(dir)
+	recordTestAnimation(test, filepath.Join(dir, "frames", "%03d.png"))
+	for _, name := range []string{"000.png", "001.png"} {
+		if _, err := os.Stat(filepath.Join(dir, "frames", name)); err != nil {
+			test.Errorf("frame %s should be written", name)
+		}
+	}
+}
+
+func TestAnimationFailedFrame(test *testing.T) {
+	anim, _ := NewAnimation("run.apng")
+	anim.add(testFrames(color.RGBA{255, 0, 0, 255})[0], time.Second)
+	if err := anim.add(image.NewRGBA(image.Rect(0, 0, 2, 2)), time.Second); err == nil {
+		test.Errorf("frame of other size should not be added")
+	}
+	if anim.Len() != 1 {
+		test.Errorf("only added frame should be counted, got %d", anim.Len())
+	}
+}
@@ -0,0 +1,138 @@
+package export
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"git.yo2.cz/drahoslav/penego/compose"
+	"git.yo2.cz/drahoslav/penego/draw"
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+// graphviz uses inches for node sizes and points (1/72 in) for positions
+const dotDpi = 72.0
+
+func dotQuote(str string) string {
+	return strconv.Quote(str)
+}
+
+func dotInches(px float64) string {
+	return strconv.FormatFloat(px/dotDpi, 'f', -1, 64)
+}
+
+// returns `pos` attribute, y axis of graphviz points up
+func dotPos(pos draw.Pos) string {
+	return dotQuote(fmt.Sprintf("%s,%s!",
+		strconv.FormatFloat(pos.X, 'f', -1, 64),
+		strconv.FormatFloat(-pos.Y, 'f', -1, 64),
+	))
+}
+
+func dotColor(c color.RGBA) string {
+	return dotQuote(fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B))
+}
+
+// returns attributes of own colours of place or transition
+func dotPaint(colorStr, fillStr string) string {
+	attrs := ""
+	if c, err := draw.ParseColor(colorStr); err == nil {
+		attrs += ", color=" + dotColor(c) + ", fontcolor=" + dotColor(c)
+	}
+	if fill, err := draw.ParseColor(fillStr); err == nil {
+		attrs += ", style=filled, fillcolor=" + dotColor(fill)
+	}
+	return attrs
+}
+
+// WriteDot writes net as graphviz digraph
+// places are circles labeled by number of tokens, transitions are boxes
+// if positioned is true, nodes have fixed positions from composition (use `neato -n`)
+func WriteDot(writer io.Writer, network net.Net, composition compose.Composition, positioned bool) error {
+	lines := []string{
+		"digraph penego {",
+		"\trankdir=LR;",
+		"\tnode [fixedsize=true];",
+	}
+	if theme := draw.CurrentTheme; theme != draw.LightTheme {
+		lines = append(lines,
+			fmt.Sprintf("\tgraph [bgcolor=%s];", dotColor(theme.Background)),
+			fmt.Sprintf("\tnode [color=%s, fontcolor=%s, fontsize=%v];", dotColor(theme.Foreground), dotColor(theme.Foreground), theme.FontSize),
+			fmt.Sprintf("\tedge [color=%s, fontcolor=%s];", dotColor(theme.Foreground), dotColor(theme.Foreground)),
+		)
+	}
+
+	posAttr := func(node compose.Composable) string {
+		if pos, ok := composition.Position(node); ok && positioned {
+			return ", pos=" + dotPos(pos)
+		}
+		return ""
+	}
+
+	for _, place := range network.Places() {
+		tokens := ""
+		if place.Tokens > 0 {
+			tokens = strconv.Itoa(place.Tokens)
+		}
+		lines = append(lines, fmt.Sprintf("\t%s [shape=circle, width=%s, label=%s, xlabel=%s%s%s];",
+			dotQuote(place.Id), dotInches(2*draw.PLACE_RADIUS), dotQuote(tokens), dotQuote(place.Description), posAttr(place),
+			dotPaint(place.Color, place.Fill),
+		))
+	}
+
+	transitions := network.Transitions()
+	names := transitions.Names()
+	for _, tran := range transitions {
+		attrs := tran.TimeFunc.String()
+		if tran.Priority != 0 {
+			attrs = "p=" + strconv.Itoa(tran.Priority)
+		}
+		label := strings.TrimSpace(tran.Description + " " + attrs)
+		lines = append(lines, fmt.Sprintf("\t%s [shape=box, width=%s, height=%s, label=\"\", xlabel=%s%s%s];",
+			dotQuote(names[tran]), dotInches(draw.TRANSITION_WIDTH), dotInches(draw.TRANSITION_HEIGHT), dotQuote(label), posAttr(tran),
+			dotPaint(tran.Color, tran.Fill),
+		))
+	}
+
+	for _, tran := range transitions {
+		for _, arc := range tran.Origins {
+			if arc.Place.Hidden() {
+				continue
+			}
+			attrs := ""
+			switch {
+			case arc.Type == net.InhibitorArc:
+				attrs = " [arrowhead=odot]"
+			case arc.Weight > 1:
+				attrs = fmt.Sprintf(" [label=%d]", arc.Weight)
+			}
+			lines = append(lines, fmt.Sprintf("\t%s -> %s%s;", dotQuote(arc.Place.Id), dotQuote(names[tran]), attrs))
+		}
+		for _, arc := range tran.Targets {
+			if arc.Place.Hidden() {
+				continue
+			}
+			attrs := ""
+			if arc.Weight > 1 {
+				attrs = fmt.Sprintf(" [label=%d]", arc.Weight)
+			}
+			lines = append(lines, fmt.Sprintf("\t%s -> %s%s;", dotQuote(names[tran]), dotQuote(arc.Place.Id), attrs))
+		}
+	}
+
+	lines = append(lines, "}")
+	_, err := io.WriteString(writer, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+func Dot(network net.Net, composition compose.Composition) error {
+	file, err := os.Create(getName("dot"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return WriteDot(file, network, composition, store.Of("dot").Bool("positions"))
+}
@@ -0,0 +1,46 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"git.yo2.cz/drahoslav/penego/compose"
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+func TestWriteDot(test *testing.T) {
+	network, _ := net.Parse(`
+		p (2) "queue"
+		q () #ff8800 #ffeecc
+		----
+		!q, p -> [2s] -> 3*q
+	`)
+	composition := compose.GetSimple(network)
+
+	buf := &bytes.Buffer{}
+	if err := WriteDot(buf, network, composition, true); err != nil {
+		test.Fatalf("WriteDot failed with %s", err)
+	}
+	dot := buf.String()
+	for _, expected := range []string{
+		`"p" [shape=circle`,
+		`label="2", xlabel="queue", pos="`,
+		`"t1" [shape=box`,
+		`xlabel="2s"`,
+		`"q" -> "t1" [arrowhead=odot];`,
+		`"p" -> "t1";`,
+		`"t1" -> "q" [label=3];`,
+		`color="#ff8800", fontcolor="#ff8800", style=filled, fillcolor="#ffeecc"];`,
+	} {
+		if !strings.Contains(dot, expected) {
+			test.Errorf("dot should contain `%s`\n%s", expected, dot)
+		}
+	}
+
+	buf.Reset()
+	WriteDot(buf, network, composition, false)
+	if strings.Contains(buf.String(), "pos=") {
+		test.Errorf("dot should not contain positions\n%s", buf)
+	}
+}
@@ -1,13 +1,18 @@
 // Package export defines ImageDrawer - implementation of draw.Drawer interface
 // which support drawing to various image formats
 // So far following are supported: PNG, SVG, PDF
+// Besides images, net can be exported as TikZ picture, graphviz DOT or JSON
 package export
 
 import (
 	"fmt"
+	"math"
 	"path/filepath"
+	"strings"
 
+	"git.yo2.cz/drahoslav/penego/compose"
 	"git.yo2.cz/drahoslav/penego/draw"
+	"git.yo2.cz/drahoslav/penego/net"
 	"git.yo2.cz/drahoslav/penego/storage"
 	"github.com/llgcode/draw2d"
 )
@@ -41,6 +46,30 @@ func (drawer ImgDrawer) DrawTransition(pos draw.Pos, attrs, description string)
 	}
 }
 
+func (drawer ImgDrawer) DrawLabel(pos draw.Pos, text string) {
+	if drawer.ctx != nil {
+		draw.Label(drawer.ctx, drawer.style, pos, text)
+	}
+}
+
+func (drawer ImgDrawer) DrawTransitionState(pos draw.Pos, enabled bool, progress float64) {
+	if drawer.ctx != nil {
+		draw.TransitionState(drawer.ctx, pos, enabled, progress)
+	}
+}
+
+func (drawer ImgDrawer) DrawToken(pos draw.Pos, n int) {
+	if drawer.ctx != nil {
+		draw.Token(drawer.ctx, drawer.style, pos, n)
+	}
+}
+
+func (drawer ImgDrawer) DrawLegend(pos draw.Pos, title, min, max string) {
+	if drawer.ctx != nil {
+		draw.Legend(drawer.ctx, drawer.style, pos, title, min, max)
+	}
+}
+
 func (drawer ImgDrawer) DrawInArc(path []draw.Pos, weight int) {
 	if drawer.ctx != nil {
 		draw.Arc(drawer.ctx, drawer.style, path, draw.In, weight)
@@ -58,6 +87,65 @@ func (drawer ImgDrawer) DrawInhibitorArc(path []draw.Pos) {
 	}
 }
 
+// Area is part of net drawn to exported image
+type Area struct {
+	Center        draw.Pos // point of net in the middle of image
+	Width, Height int      // size of image in px (pt for pdf)
+}
+
+// AreaOf returns area to be exported according to export settings
+// if fit is set, area contains whole composition surrounded by margin
+// otherwise it has set width and height and is centered where gui view is
+func AreaOf(composition compose.Composition) Area {
+	if !store.Bool("fit") {
+		gui := storage.Of("gui")
+		return Area{
+			draw.Pos{gui.Float("offset.x"), gui.Float("offset.y")},
+			store.Int("width"), store.Int("height"),
+		}
+	}
+	margin := float64(store.Int("margin"))
+	left, top, right, bottom := composition.Bounds()
+	return Area{
+		draw.Pos{(left + right) / 2, (top + bottom) / 2},
+		int(math.Ceil(right - left + 2*margin)), int(math.Ceil(bottom - top + 2*margin)),
+	}
+}
+
+// Papers are sizes of paper formats in pt, portrait
+var Papers = map[string][2]int{
+	"a3":     {842, 1190},
+	"a4":     {595, 842},
+	"a5":     {420, 595},
+	"letter": {612, 792},
+	"legal":  {612, 1008},
+}
+
+// ParseSize parses size of image or page given as WxH or name of paper format (e.g. a4)
+func ParseSize(str string) (width, height int, err error) {
+	if paper, ok := Papers[strings.ToLower(str)]; ok {
+		return paper[0], paper[1], nil
+	}
+	if _, err := fmt.Sscanf(strings.ToLower(str), "%dx%d", &width, &height); err != nil || width <= 0 || height <= 0 {
+		return 0, 0, fmt.Errorf("invalid size %s, expected WxH or one of a3, a4, a5, letter, legal", str)
+	}
+	return width, height, nil
+}
+
+// returns scale of png image given by dpi setting, 72 dpi is 1px for 1pt
+func pngScale() float64 {
+	if dpi := store.Int("dpi"); dpi > 0 {
+		return float64(dpi) / 72
+	}
+	return 1
+}
+
+// returns size of png image of area in px
+func pngSize(area Area) (width, height int) {
+	scale := pngScale()
+	return int(float64(area.Width) * scale), int(float64(area.Height) * scale)
+}
+
 func getName(ext string) string {
 	filename := store.Of(ext).String("filename")
 	return filename
@@ -69,15 +157,21 @@ func setName(filename string) string {
 	return ext
 }
 
-func ByName(filename string, composeNet func(draw.Drawer)) error {
+func ByName(filename string, network net.Net, composition compose.Composition) error {
 	ext := setName(filename)
 	switch ext {
 	case "png":
-		return Png(composeNet)
+		return Png(composition.DrawWith, AreaOf(composition))
 	case "svg":
-		return Svg(composeNet)
+		return Svg(composition.DrawWith, AreaOf(composition))
 	case "pdf":
-		return Pdf(composeNet)
+		return Pdf(composition.DrawWith, AreaOf(composition))
+	case "dot":
+		return Dot(network, composition)
+	case "json":
+		return Json(network, composition)
+	case "tex":
+		return Tikz(composition.DrawWith)
 	default:
 		return fmt.Errorf("Unknown export format %s", ext)
 	}
@@ -0,0 +1,89 @@
+package export
+
+import (
+	"testing"
+
+	"git.yo2.cz/drahoslav/penego/compose"
+	"git.yo2.cz/drahoslav/penego/draw"
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+func TestParseSize(test *testing.T) {
+	for str, expected := range map[string][2]int{
+		"a4":       {595, 842},
+		"Letter":   {612, 792},
+		"800x600":  {800, 600},
+		"800X600":  {800, 600},
+		"800":      {0, 0},
+		"0x600":    {0, 0},
+		"a4 paper": {0, 0},
+	} {
+		width, height, err := ParseSize(str)
+		if width != expected[0] || height != expected[1] {
+			test.Errorf("size %s should be %v, got %dx%d", str, expected, width, height)
+		}
+		if (err == nil) != (width > 0) {
+			test.Errorf("size %s should fail iff it is invalid, got %v", str, err)
+		}
+	}
+}
+
+func TestAreaOfFit(test *testing.T) {
+	network, _ := net.Parse(`
+		p ()
+		q ()
+		----
+		p -> t[] -> q
+	`)
+	composition := compose.New()
+	composition.Move(network.Places()[0], 0, 0)
+	composition.Move(network.Transitions()[0], 90, 0)
+	composition.Move(network.Places()[1], 180, 30)
+	store.Set("fit", true).Set("margin", 10)
+	defer func() {
+		store.Set("fit", false).Set("margin", 0)
+	}()
+
+	area := AreaOf(composition)
+	// from left of p to right of q, from top of t to bottom of q
+	left, right := -draw.PLACE_RADIUS, 180+draw.PLACE_RADIUS
+	top, bottom := -draw.TRANSITION_HEIGHT/2, 30+draw.PLACE_RADIUS
+	expected := Area{draw.Pos{(left + right) / 2, (top + bottom) / 2}, int(right - left + 20), int(bottom - top + 20)}
+	if area != expected {
+		test.Errorf("area should fit net with margin %v, got %v", expected, area)
+	}
+}
+
+func TestPngSize(test *testing.T) {
+	defer store.Set("dpi", 0)
+	for dpi, expected := range map[int][2]int{
+		0:   {612, 792},
+		72:  {612, 792},
+		300: {2550, 3300},
+	} {
+		store.Set("dpi", dpi)
+		width, height, _ := ParseSize("letter")
+		w, h := pngSize(Area{draw.Pos{0, 0}, width, height})
+		if w != expected[0] || h != expected[1] {
+			test.Errorf("letter at %d dpi should be %v px, got %dx%d", dpi, expected, w, h)
+		}
+	}
+}
+
+func TestPageCenters(test *testing.T) {
+	area := Area{draw.Pos{0, 0}, 300, 100}
+	centers := pageCenters(area, 120, 120, 10)
+	// 280 wide content on pages with 100 usable, 80 high on one page
+	expected := []draw.Pos{{-100, 0}, {0, 0}, {100, 0}}
+	if len(centers) != len(expected) {
+		test.Fatalf("area should be split into %d pages, got %v", len(expected), centers)
+	}
+	for i := range expected {
+		if centers[i] != expected[i] {
+			test.Errorf("page %d should be centered at %v, got %v", i, expected[i], centers[i])
+		}
+	}
+	if centers := pageCenters(area, 500, 500, 10); len(centers) != 1 || centers[0] != area.Center {
+		test.Errorf("area should fit on single page, got %v", centers)
+	}
+}
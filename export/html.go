@@ -0,0 +1,210 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"time"
+
+	"git.yo2.cz/drahoslav/penego/compose"
+	"git.yo2.cz/drahoslav/penego/draw"
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+// Trace is recorded run of simulation
+// it holds marking of places after every state change
+type Trace struct {
+	Places   []*net.Place
+	Times    []time.Duration
+	Markings [][]int
+}
+
+// RecordTrace runs simulation of network and records at most maxSteps state changes
+// network is restored to its initial marking afterwards
+func RecordTrace(network net.Net, start, end time.Duration, maxSteps int) Trace {
+	trace := Trace{Places: network.Places()}
+	sim := net.NewSimulation(start, end, network)
+	sim.DoEveryStateChange(func(before, now time.Duration) {
+		if len(trace.Times) >= maxSteps {
+			return
+		}
+		marking := make([]int, len(trace.Places))
+		for i, place := range trace.Places {
+			marking[i] = place.Tokens
+		}
+		trace.Times = append(trace.Times, now)
+		trace.Markings = append(trace.Markings, marking)
+	})
+	sim.Init()
+	for len(trace.Times) < maxSteps && sim.Step() {
+	}
+	sim.Stop()
+	return trace
+}
+
+// data of player page, passed to its script as JSON
+type playerData struct {
+	Width    int        `json:"width"`
+	Height   int        `json:"height"`
+	Origin   draw.Pos   `json:"origin"` // where net coordinates start in svg
+	Places   []draw.Pos `json:"places"`
+	Hidden   []bool     `json:"hidden"`
+	Times    []string   `json:"times"`
+	Markings [][]int    `json:"markings"`
+	Delay    int64      `json:"delay"` // ms between steps when playing
+}
+
+func newPlayerData(composition compose.Composition, area Area, trace Trace, delay time.Duration) playerData {
+	data := playerData{
+		Width:    area.Width,
+		Height:   area.Height,
+		Origin:   draw.Pos{float64(area.Width)/2 - area.Center.X, float64(area.Height)/2 - area.Center.Y},
+		Places:   []draw.Pos{},
+		Hidden:   []bool{},
+		Times:    []string{},
+		Markings: trace.Markings,
+		Delay:    int64(delay / time.Millisecond),
+	}
+	for _, place := range trace.Places {
+		pos, ok := composition.Position(place)
+		data.Places = append(data.Places, pos)
+		data.Hidden = append(data.Hidden, !ok || place.Hidden())
+	}
+	for _, t := range trace.Times {
+		data.Times = append(data.Times, t.String())
+	}
+	if data.Markings == nil {
+		data.Markings = [][]int{}
+	}
+	return data
+}
+
+// Html writes self-contained page with svg image of net and player of recorded trace of its simulation
+func Html(composition compose.Composition, trace Trace, delay time.Duration) error {
+	file, err := os.Create(getName("html"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return WriteHtml(file, composition, trace, delay)
+}
+
+func WriteHtml(w io.Writer, composition compose.Composition, trace Trace, delay time.Duration) error {
+	// places are drawn empty, tokens are drawn by player
+	tokens := make([]int, len(trace.Places))
+	for i, place := range trace.Places {
+		tokens[i], place.Tokens = place.Tokens, 0
+	}
+	area := AreaOf(composition)
+	img := renderSvg(composition.DrawWith, area)
+	for i, place := range trace.Places {
+		place.Tokens = tokens[i]
+	}
+
+	fg := draw.CurrentTheme.Foreground
+	svg, err := xml.Marshal(img)
+	if err != nil {
+		return err
+	}
+	return playerTemplate.Execute(w, struct {
+		Svg   template.HTML
+		Data  playerData
+		Color string
+	}{template.HTML(svg), newPlayerData(composition, area, trace, delay), fmt.Sprintf("#%02x%02x%02x", fg.R, fg.G, fg.B)})
+}
+
+var playerTemplate = template.Must(template.New("player").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>penego</title>
+<style>
+	body { font-family: sans-serif; }
+	#controls { display: flex; align-items: center; gap: 8px; margin: 8px 0; }
+	#scrubber { flex: 1; max-width: 600px; }
+	#tokens text { font: 20px monospace; text-anchor: middle; dominant-baseline: central; }
+</style>
+</head>
+<body>
+<div id="controls">
+	<button id="play">play</button>
+	<button id="step">step</button>
+	<input id="scrubber" type="range" min="0" value="0">
+	<span id="time"></span>
+</div>
+<svg xmlns="http://www.w3.org/2000/svg" width="{{.Data.Width}}" height="{{.Data.Height}}">
+{{.Svg}}
+<g id="tokens" fill="{{.Color}}"></g>
+</svg>
+<script>
+(function () {
+	var data = {{.Data}};
+	var ns = "http://www.w3.org/2000/svg";
+	var tokens = document.getElementById("tokens");
+	var scrubber = document.getElementById("scrubber");
+	var playButton = document.getElementById("play");
+	var timeLabel = document.getElementById("time");
+	var texts = data.places.map(function (pos, i) {
+		var text = document.createElementNS(ns, "text");
+		text.setAttribute("x", data.origin.X + pos.X);
+		text.setAttribute("y", data.origin.Y + pos.Y);
+		if (!data.hidden[i]) {
+			tokens.appendChild(text);
+		}
+		return text;
+	});
+	var current = 0, timer = null;
+	scrubber.max = Math.max(data.markings.length - 1, 0);
+
+	function show(step) {
+		if (data.markings.length == 0) {
+			timeLabel.textContent = "no state changes";
+			return;
+		}
+		current = step;
+		scrubber.value = step;
+		timeLabel.textContent = data.times[step] + " (" + (step + 1) + "/" + data.markings.length + ")";
+		data.markings[step].forEach(function (n, i) {
+			texts[i].textContent = n > 0 ? n : "";
+		});
+	}
+	function pause() {
+		clearInterval(timer);
+		timer = null;
+		playButton.textContent = "play";
+	}
+	function step() {
+		if (current + 1 >= data.markings.length) {
+			pause();
+			return;
+		}
+		show(current + 1);
+	}
+
+	playButton.onclick = function () {
+		if (timer != null) {
+			pause();
+			return;
+		}
+		if (current + 1 >= data.markings.length) {
+			show(0);
+		}
+		timer = setInterval(step, data.delay);
+		playButton.textContent = "pause";
+	};
+	document.getElementById("step").onclick = function () {
+		pause();
+		step();
+	};
+	scrubber.oninput = function () {
+		pause();
+		show(parseInt(scrubber.value, 10));
+	};
+	show(0);
+})();
+</script>
+</body>
+</html>
+`))
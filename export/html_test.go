@@ -0,0 +1,69 @@
+package export
+
+import (
+	"testing"
+	"time"
+
+	"git.yo2.cz/drahoslav/penego/compose"
+	"git.yo2.cz/drahoslav/penego/draw"
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+func TestRecordTrace(test *testing.T) {
+	network, _ := net.Parse(`
+		p (2)
+		q ()
+		----
+		p -> [1s] -> q
+	`)
+	trace := RecordTrace(network, 0, time.Minute, 10)
+
+	// both tokens are taken at once, as transition is enabled twice
+	expectedTimes := []time.Duration{0, time.Second, time.Second}
+	expectedMarkings := [][]int{{2, 0}, {1, 1}, {0, 2}}
+	if len(trace.Times) != len(expectedTimes) {
+		test.Fatalf("trace should have %d steps, got %v", len(expectedTimes), trace.Times)
+	}
+	for i := range expectedTimes {
+		if trace.Times[i] != expectedTimes[i] {
+			test.Errorf("step %d should be at %s, got %s", i, expectedTimes[i], trace.Times[i])
+		}
+		for j, n := range expectedMarkings[i] {
+			if trace.Markings[i][j] != n {
+				test.Errorf("marking of step %d should be %v, got %v", i, expectedMarkings[i], trace.Markings[i])
+				break
+			}
+		}
+	}
+	if tokens := network.Places()[0].Tokens; tokens != 2 {
+		test.Errorf("initial marking should be restored, got %d", tokens)
+	}
+
+	if short := RecordTrace(network, 0, time.Minute, 2); len(short.Times) != 2 {
+		test.Errorf("trace should be limited to 2 steps, got %d", len(short.Times))
+	}
+}
+
+func TestPlayerData(test *testing.T) {
+	network, _ := net.Parse(`
+		p (1)
+		----
+		p -> t[]
+	`)
+	p := network.Places()[0]
+	composition := compose.New()
+	composition.Move(p, 30, -15)
+	composition.Move(network.Transitions()[0], 120, -15)
+	area := Area{draw.Pos{0, 0}, 200, 100}
+
+	data := newPlayerData(composition, area, Trace{Places: []*net.Place{p}}, 100*time.Millisecond)
+	if data.Origin != (draw.Pos{100, 50}) {
+		test.Errorf("origin should be in the middle of image, got %v", data.Origin)
+	}
+	if len(data.Places) != 1 || data.Places[0] != (draw.Pos{30, -15}) || data.Hidden[0] {
+		test.Errorf("place should be at its position, got %v %v", data.Places, data.Hidden)
+	}
+	if data.Delay != 100 || data.Markings == nil {
+		test.Errorf("delay should be in ms and markings not null, got %d %v", data.Delay, data.Markings)
+	}
+}
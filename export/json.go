@@ -0,0 +1,18 @@
+package export
+
+import (
+	"os"
+
+	"git.yo2.cz/drahoslav/penego/compose"
+	"git.yo2.cz/drahoslav/penego/net"
+	"git.yo2.cz/drahoslav/penego/netjson"
+)
+
+func Json(network net.Net, composition compose.Composition) error {
+	file, err := os.Create(getName("json"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return netjson.Write(file, network, composition)
+}
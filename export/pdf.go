@@ -1,46 +1,76 @@
 package export
 
 import (
+	"math"
+
 	"git.yo2.cz/drahoslav/penego/draw"
+	"github.com/jung-kurt/gofpdf"
 	"github.com/llgcode/draw2d"
 	"github.com/llgcode/draw2d/draw2dpdf"
 )
 
-func Pdf(composeNet func(draw.Drawer)) error {
+// returns centers of pages, which together cover area
+// pages overlap by margin, so nothing is lost on their edges
+func pageCenters(area Area, pageWidth, pageHeight, margin float64) []draw.Pos {
+	tiles := func(length, page float64) int {
+		n := math.Ceil((length - 2*margin) / (page - 2*margin))
+		if n < 1 || math.IsNaN(n) || math.IsInf(n, 0) {
+			return 1
+		}
+		return int(n)
+	}
+	cols := tiles(float64(area.Width), pageWidth)
+	rows := tiles(float64(area.Height), pageHeight)
+	// covered part may be bigger than area, keep it centered
+	stepX, stepY := pageWidth-2*margin, pageHeight-2*margin
+	left := area.Center.X - stepX*float64(cols)/2
+	top := area.Center.Y - stepY*float64(rows)/2
+
+	centers := []draw.Pos{}
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			centers = append(centers, draw.Pos{left + stepX*(float64(col)+0.5), top + stepY*(float64(row)+0.5)})
+		}
+	}
+	return centers
+}
+
+// Pdf draws area of net to page of the same size
+// if multipage is set, it is split into pages of set width and height instead
+func Pdf(composeNet func(draw.Drawer), area Area) error {
 	draw2d.SetFontFolder("fonts")
 	draw2d.SetFontNamer(func(fd draw2d.FontData) string {
 		return fd.Name
 	})
 
-	width, height := store.Int("width"), store.Int("height")
-
-	orientation := ""
-	if width > height {
-		orientation = "L"
-	} else {
-		orientation = "P"
+	width, height := area.Width, area.Height
+	centers := []draw.Pos{area.Center}
+	if store.Bool("multipage") {
+		width, height = store.Int("width"), store.Int("height")
+		centers = pageCenters(area, float64(width), float64(height), float64(store.Int("margin")))
 	}
 
-	paper := ""
-	switch width*height {
-	case 1190*841:
-		paper = "a3"
-	case 842*595:
-		paper = "a4"
-	case 595 *420:
-		paper = "a5"
-	}
+	img := gofpdf.NewCustom(&gofpdf.InitType{
+		UnitStr:    "pt",
+		Size:       gofpdf.SizeType{Wd: float64(width), Ht: float64(height)},
+		FontDirStr: draw2d.GetFontFolder(),
+	})
+	// same as draw2dpdf.NewPdf does, to be compatible with draw2d
+	img.SetMargins(0, 0, 0)
+	img.SetLineCapStyle("round")
+	img.SetLineJoinStyle("round")
 
-	img := draw2dpdf.NewPdf(orientation, "pt", paper)
-	drawer := &ImgDrawer{draw2dpdf.NewGraphicContext(img), 0}
+	for _, center := range centers {
+		img.AddPage()
+		drawer := &ImgDrawer{draw2dpdf.NewGraphicContext(img), draw.DefaultStyle}
 
-	drawer.ctx.Save() // required for pdf backend to call save before translate
-	draw.Init(drawer.ctx, width, height)
-	// drawer.ctx.SetFontData(draw2d.FontData{Name: "courier"}) // TODO use gomono
-	if store.Bool("background") {
-		draw.Clean(drawer.ctx, width, height) // background
+		drawer.ctx.Save() // required for pdf backend to call save before translate
+		draw.InitAt(drawer.ctx, width, height, center)
+		if store.Bool("background") {
+			draw.CleanAt(drawer.ctx, width, height, center) // background
+		}
+		composeNet(drawer)
+		drawer.ctx.Restore()
 	}
-	composeNet(drawer)
-	drawer.ctx.Restore()
 	return draw2dpdf.SaveToPdfFile(getName("pdf"), img)
 }
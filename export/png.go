@@ -7,17 +7,29 @@ import (
 	"github.com/llgcode/draw2d/draw2dimg"
 )
 
-func Png(composeNet func(draw.Drawer)) error {
-	width, height := store.Int("width"), store.Int("height")
+// returns image of area of net scaled according to dpi setting
+func renderPng(composeNet func(draw.Drawer), area Area, background bool) *image.RGBA {
+	scale := pngScale()
+	width, height := pngSize(area)
 
 	img := image.NewRGBA(image.Rect(0, 0, width, height))
-	drawer := &ImgDrawer{draw2dimg.NewGraphicContext(img), 0}
+	drawer := &ImgDrawer{draw2dimg.NewGraphicContext(img), draw.DefaultStyle}
 
-	draw.Init(drawer.ctx, width, height)
-	if store.Bool("background") {
-		draw.Clean(drawer.ctx, width, height) // background
+	drawer.ctx.Scale(scale, scale)
+	draw.InitAt(drawer.ctx, area.Width, area.Height, area.Center)
+	if background {
+		draw.CleanAt(drawer.ctx, area.Width, area.Height, area.Center)
 	}
 	composeNet(drawer)
+	return img
+}
+
+// Frame renders area of net to image, always with background
+func Frame(composeNet func(draw.Drawer), area Area) *image.RGBA {
+	return renderPng(composeNet, area, true)
+}
 
+func Png(composeNet func(draw.Drawer), area Area) error {
+	img := renderPng(composeNet, area, store.Bool("background"))
 	return draw2dimg.SaveToPngFile(getName("png"), img)
 }
@@ -1,21 +1,49 @@
 package export
 
 import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+
 	"git.yo2.cz/drahoslav/penego/draw"
 	"github.com/llgcode/draw2d/draw2dsvg"
 )
 
-func Svg(composeNet func(draw.Drawer)) error {
+// returns svg image of area of net
+func renderSvg(composeNet func(draw.Drawer), area Area) *draw2dsvg.Svg {
 	img := draw2dsvg.NewSvg()
-	drawer := &ImgDrawer{draw2dsvg.NewGraphicContext(img), 0}
-
-	width, height := store.Int("width"), store.Int("height")
+	drawer := &ImgDrawer{draw2dsvg.NewGraphicContext(img), draw.DefaultStyle}
 
-	draw.Init(drawer.ctx, width, height)
+	draw.InitAt(drawer.ctx, area.Width, area.Height, area.Center)
 	if store.Bool("background") {
-		draw.Clean(drawer.ctx, width, height) // background
+		draw.CleanAt(drawer.ctx, area.Width, area.Height, area.Center) // background
 	}
 	composeNet(drawer)
+	return img
+}
+
+// writes svg document of image with size of area, which draw2dsvg does not set on its own
+func writeSvg(w io.Writer, img *draw2dsvg.Svg, area Area) error {
+	content, err := xml.MarshalIndent(img, "", "\t")
+	if err != nil {
+		return err
+	}
+	size := fmt.Sprintf(`<svg width="%d" height="%d" viewBox="0 0 %d %d"`, area.Width, area.Height, area.Width, area.Height)
+	content = bytes.Replace(content, []byte("<svg"), []byte(size), 1)
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
 
-	return draw2dsvg.SaveToSvgFile(getName("svg"), img)
+func Svg(composeNet func(draw.Drawer), area Area) error {
+	file, err := os.Create(getName("svg"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return writeSvg(file, renderSvg(composeNet, area), area)
 }
@@ -0,0 +1,21 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"git.yo2.cz/drahoslav/penego/draw"
+	"github.com/llgcode/draw2d/draw2dsvg"
+)
+
+func TestWriteSvg(test *testing.T) {
+	var buf bytes.Buffer
+	err := writeSvg(&buf, draw2dsvg.NewSvg(), Area{draw.Pos{0, 0}, 400, 300})
+	if err != nil {
+		test.Fatalf("svg should be written, got %s", err)
+	}
+	if str := buf.String(); !strings.Contains(str, `<svg width="400" height="300" viewBox="0 0 400 300"`) {
+		test.Errorf("svg should have size of area, got\n%s", str)
+	}
+}
@@ -0,0 +1,232 @@
+package export
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"git.yo2.cz/drahoslav/penego/draw"
+)
+
+// TikzUnit is length of one pixel of composition in centimeters
+var TikzUnit = 1 / 60.0
+
+// TikzDrawer implements draw.Drawer and draw.NodeDrawer
+// it collects drawn elements and writes them as tikzpicture using petri and arrows libraries
+type TikzDrawer struct {
+	nodes    []tikzNode
+	arcs     []tikzArc
+	labels   []string
+	style    draw.Style
+	from, to interface{} // nodes drawn element belongs to
+}
+
+type tikzNode struct {
+	prefix  string // p for places, t for transitions
+	pos     draw.Pos
+	options []string
+	content string
+	node    interface{}
+}
+
+type tikzArc struct {
+	path     []draw.Pos
+	style    string
+	weight   int
+	from, to interface{}
+}
+
+func NewTikzDrawer() *TikzDrawer {
+	return &TikzDrawer{}
+}
+
+var tikzEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`#`, `\#`,
+	`$`, `\$`,
+	`%`, `\%`,
+	`&`, `\&`,
+	`_`, `\_`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+)
+
+func tikzNum(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+func tikzLength(px float64) string {
+	return strconv.FormatFloat(px*TikzUnit, 'g', 4, 64) + "cm"
+}
+
+func tikzCoord(pos draw.Pos) string {
+	return fmt.Sprintf("(%s,%s)", tikzNum(pos.X), tikzNum(pos.Y))
+}
+
+func tikzColor(c color.RGBA) string {
+	return fmt.Sprintf("{rgb,255:red,%d;green,%d;blue,%d}", c.R, c.G, c.B)
+}
+
+func (drawer *TikzDrawer) SetNodes(from, to interface{}) {
+	drawer.from, drawer.to = from, to
+}
+
+// only own colours of elements are exported, highlighting and fading is not
+func (drawer *TikzDrawer) SetStyle(style draw.Style) {
+	drawer.style = style
+}
+
+// returns options of own colours of element
+func (drawer *TikzDrawer) paint() []string {
+	options := []string{}
+	c, fill := drawer.style.Colors()
+	if c.A != 0 {
+		options = append(options, "draw="+tikzColor(c), "text="+tikzColor(c))
+	}
+	if fill.A != 0 {
+		options = append(options, "fill="+tikzColor(fill))
+	}
+	return options
+}
+
+func (drawer *TikzDrawer) DrawPlace(pos draw.Pos, n int, description string) {
+	attrs := []string{"place", "minimum size=" + tikzLength(2*draw.PLACE_RADIUS)}
+	content := ""
+	if n > 0 && n < 6 {
+		attrs = append(attrs, fmt.Sprintf("tokens=%d", n))
+	} else if n >= 6 {
+		content = strconv.Itoa(n)
+	}
+	if description != "" {
+		attrs = append(attrs, "label=above:{"+tikzEscaper.Replace(description)+"}")
+	}
+	attrs = append(attrs, drawer.paint()...)
+	drawer.nodes = append(drawer.nodes, tikzNode{"p", pos, attrs, content, drawer.from})
+}
+
+func (drawer *TikzDrawer) DrawTransition(pos draw.Pos, attrs, description string) {
+	options := []string{
+		"transition",
+		"minimum width=" + tikzLength(draw.TRANSITION_WIDTH),
+		"minimum height=" + tikzLength(draw.TRANSITION_HEIGHT),
+	}
+	if description != "" {
+		options = append(options, "label=above:{"+tikzEscaper.Replace(description)+"}")
+	}
+	if attrs != "" {
+		options = append(options, "label=below:{"+tikzEscaper.Replace(attrs)+"}")
+	}
+	options = append(options, drawer.paint()...)
+	drawer.nodes = append(drawer.nodes, tikzNode{"t", pos, options, "", drawer.from})
+}
+
+func (drawer *TikzDrawer) DrawLabel(pos draw.Pos, text string) {
+	options := ""
+	if c, _ := drawer.style.Colors(); c.A != 0 {
+		options = "[text=" + tikzColor(c) + "] "
+	}
+	drawer.labels = append(drawer.labels, fmt.Sprintf("\t\\node %sat %s {%s};", options, tikzCoord(pos), tikzEscaper.Replace(text)))
+}
+
+// arcs are drawn before nodes they refer to, so they are kept until picture is written
+func (drawer *TikzDrawer) arc(path []draw.Pos, style string, weight int) {
+	drawer.arcs = append(drawer.arcs, tikzArc{append([]draw.Pos{}, path...), style, weight, drawer.from, drawer.to})
+}
+
+func (drawer *TikzDrawer) DrawInArc(path []draw.Pos, weight int) {
+	drawer.arc(path, "->", weight)
+}
+
+func (drawer *TikzDrawer) DrawOutArc(path []draw.Pos, weight int) {
+	drawer.arc(path, "->", weight)
+}
+
+func (drawer *TikzDrawer) DrawInhibitorArc(path []draw.Pos) {
+	drawer.arc(path, "-o", 1)
+}
+
+// Write writes collected elements as tikzpicture
+// nodes are named p1, p2... and t1, t2... in reading order, so output is stable
+func (drawer *TikzDrawer) Write(writer io.Writer) error {
+	lines := []string{
+		"% requires \\usetikzlibrary{petri,arrows}",
+		fmt.Sprintf("\\begin{tikzpicture}[x=%s, y=-%s, >=stealth%s]", tikzLength(1), tikzLength(1), tikzTheme()),
+	}
+
+	sort.SliceStable(drawer.nodes, func(i, j int) bool {
+		a, b := drawer.nodes[i].pos, drawer.nodes[j].pos
+		return a.Y < b.Y || (a.Y == b.Y && a.X < b.X)
+	})
+	names := map[interface{}]string{}
+	counts := map[string]int{}
+	for _, node := range drawer.nodes {
+		counts[node.prefix]++
+		name := node.prefix + strconv.Itoa(counts[node.prefix])
+		if node.node != nil {
+			names[node.node] = name
+		}
+		lines = append(lines, fmt.Sprintf("\t\\node[%s] (%s) at %s {%s};",
+			strings.Join(node.options, ", "), name, tikzCoord(node.pos), node.content,
+		))
+	}
+	// returns name of node, or coordinate of arc end if node is not known
+	ref := func(node interface{}, pos draw.Pos) string {
+		if name, ok := names[node]; ok && node != nil {
+			return "(" + name + ")"
+		}
+		return tikzCoord(pos)
+	}
+
+	arcLines := []string{}
+	for _, arc := range drawer.arcs {
+		points := []string{}
+		for i, pos := range arc.path {
+			if i == 0 {
+				points = append(points, ref(arc.from, pos))
+			} else if i == len(arc.path)-1 {
+				points = append(points, ref(arc.to, pos))
+			} else {
+				points = append(points, tikzCoord(pos))
+			}
+		}
+		label := ""
+		if arc.weight > 1 {
+			label = fmt.Sprintf(" node[auto, pos=0.5] {%d}", arc.weight)
+		}
+		arcLines = append(arcLines, fmt.Sprintf("\t\\draw[%s] %s%s;", arc.style, strings.Join(points, " -- "), label))
+	}
+	sort.Strings(arcLines)
+	lines = append(lines, arcLines...)
+	sort.Strings(drawer.labels)
+	lines = append(lines, drawer.labels...)
+	lines = append(lines, "\\end{tikzpicture}")
+	_, err := io.WriteString(writer, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// returns options of picture for theme other than default one
+func tikzTheme() string {
+	theme := draw.CurrentTheme
+	if theme == draw.LightTheme {
+		return ""
+	}
+	return fmt.Sprintf(", color=%s, every node/.style={fill=%s}", tikzColor(theme.Foreground), tikzColor(theme.Background))
+}
+
+func Tikz(composeNet func(draw.Drawer)) error {
+	drawer := NewTikzDrawer()
+	composeNet(drawer)
+
+	file, err := os.Create(getName("tex"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return drawer.Write(file)
+}
@@ -0,0 +1,80 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"git.yo2.cz/drahoslav/penego/compose"
+	"git.yo2.cz/drahoslav/penego/draw"
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+func TestTikz(test *testing.T) {
+	network, _ := net.Parse(`
+		p (2) "50% of_all"
+		q (7)
+		----
+		!q, p -> [2s] -> 3*q
+	`)
+	composition := compose.New()
+	places, trans := network.Places(), network.Transitions()
+	composition.Move(places[0], 0, 0)
+	composition.Move(places[1], 180, 0)
+	composition.Move(trans[0], 90, 0)
+	composition.SetPathPositions(trans[0], places[1], []draw.Pos{{90, 90}, {180, 90}})
+
+	drawer := NewTikzDrawer()
+	composition.DrawWith(drawer)
+	buf := &bytes.Buffer{}
+	if err := drawer.Write(buf); err != nil {
+		test.Fatalf("Write failed with %s", err)
+	}
+	tikz := buf.String()
+	for _, expected := range []string{
+		`\usetikzlibrary{petri,arrows}`,
+		`\begin{tikzpicture}`,
+		`tokens=2] (p`,
+		`\node at (0,-35) {50\% of\_all};`,
+		`] (p1) at (0,0) {`,
+		`{7};`,
+		`\node[transition`,
+		`label=below:{2s}] (t1) at (90,0) {};`,
+		`\draw[-o] (p2) -- (180,90) -- (90,90) -- (t1);`,
+		`\draw[->] (t1) -- (90,90) -- (180,90) -- (p2) node[auto, pos=0.5] {3};`,
+		`\end{tikzpicture}`,
+	} {
+		if !strings.Contains(tikz, expected) {
+			test.Errorf("tikz should contain `%s`\n%s", expected, tikz)
+		}
+	}
+}
+
+func TestTikzNodesAtSamePosition(test *testing.T) {
+	network, _ := net.Parse(`
+		p (2)
+		r ()
+		----
+		p -> t[]
+	`)
+	composition := compose.New()
+	places, trans := network.Places(), network.Transitions()
+	composition.Move(places[0], 0, 0)
+	composition.Move(places[1], 0, 0)
+	composition.Move(trans[0], 90, 0)
+
+	drawer := NewTikzDrawer()
+	composition.DrawWith(drawer)
+	buf := &bytes.Buffer{}
+	if err := drawer.Write(buf); err != nil {
+		test.Fatalf("Write failed with %s", err)
+	}
+	tikz := buf.String()
+	name := "(p1)"
+	if !strings.Contains(tikz, "tokens=2] (p1)") {
+		name = "(p2)"
+	}
+	if expected := `\draw[->] ` + name + ` -- (t1);`; !strings.Contains(tikz, expected) {
+		test.Errorf("arc should refer to its place %s\n%s", name, tikz)
+	}
+}
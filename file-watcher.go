@@ -2,8 +2,10 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/fsnotify/fsnotify"
 )
@@ -13,6 +15,7 @@ type Watcher struct {
 	close  func()
 	action func()
 	isOn   func() bool
+	write  func(string) error // writes watched file without calling callback
 }
 
 func makeFileWatcher(callback func(string)) Watcher {
@@ -68,5 +71,36 @@ func makeFileWatcher(callback func(string)) Watcher {
 		return currentFile != ""
 	}
 
-	return Watcher{watch, end, action, isOn}
+	// content is written to temporary file, which then replaces watched one at once,
+	// so neither half written file nor the change itself is reported
+	write := func(content string) error {
+		file := currentFile
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		tmp, err := ioutil.TempFile(filepath.Dir(file), "."+filepath.Base(file))
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmp.Name()) // if not renamed
+		_, err = tmp.WriteString(content)
+		if closeErr := tmp.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = os.Chmod(tmp.Name(), info.Mode())
+		}
+		if err != nil {
+			return err
+		}
+		watcher.Remove(file) // watch belongs to replaced file
+		err = os.Rename(tmp.Name(), file)
+		if addErr := watcher.Add(file); err == nil {
+			err = addErr
+		}
+		return err
+	}
+
+	return Watcher{watch, end, action, isOn, write}
 }
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"git.yo2.cz/drahoslav/penego/compose"
+	"git.yo2.cz/drahoslav/penego/greatspn"
+	"git.yo2.cz/drahoslav/penego/lola"
+	"git.yo2.cz/drahoslav/penego/net"
+	"git.yo2.cz/drahoslav/penego/netjson"
+	"git.yo2.cz/drahoslav/penego/pnml"
+	"git.yo2.cz/drahoslav/penego/tina"
+)
+
+// imports net from file of other tool, format is chosen by extension
+//
+//	.pnml .xml - PNML (PIPE, CPN tools)
+//	.net - GreatSPN if it starts with |0| header, TINA otherwise
+//	.def - GreatSPN, reads .net file of same name
+//	.lola - LoLA
+//	.json - JSON, see package netjson
+func importNet(filename string, strict bool) (net.Net, compose.Composition, error) {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if ext == ".def" {
+		filename = strings.TrimSuffix(filename, filepath.Ext(filename)) + ".net"
+	}
+	file, err := os.Open(filename)
+	if err != nil {
+		return net.Net{}, compose.New(), err
+	}
+	defer file.Close()
+	reader := bufio.NewReader(file)
+
+	withComposition := func(network net.Net, err error) (net.Net, compose.Composition, error) {
+		if err != nil {
+			return net.Net{}, compose.New(), err
+		}
+		composition := Compose(network)
+		composition.CenterTo(0, 0)
+		return network, composition, nil
+	}
+
+	switch ext {
+	case ".pnml", ".xml":
+		if strict {
+			return pnml.ParseStrict(reader)
+		}
+		return pnml.Parse(reader)
+	case ".net", ".def":
+		header, _ := reader.Peek(3)
+		if bytes.Equal(header, []byte("|0|")) {
+			return greatspn.Parse(reader)
+		}
+		return withComposition(tina.Parse(reader))
+	case ".lola":
+		return withComposition(lola.Parse(reader))
+	case ".json":
+		network, composition, err := netjson.Parse(reader, Compose)
+		if err == nil {
+			composition.CenterTo(0, 0)
+		}
+		return network, composition, err
+	default:
+		return net.Net{}, compose.New(), fmt.Errorf("Unknown import format %s", ext)
+	}
+}
+
+// exports net to file of other tool, format is chosen by extension
+//
+//	.net - TINA
+//	.def - GreatSPN, writes also .net file of same name
+//	.lola - LoLA
+//
+// returns false if extension is not one of those
+func exportNet(filename string, network net.Net, composition compose.Composition) (bool, error) {
+	create := func(filename string, write func(file *os.File) error) error {
+		file, err := os.Create(filename)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		return write(file)
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".net":
+		return true, create(filename, func(file *os.File) error {
+			return tina.Write(file, network)
+		})
+	case ".lola":
+		return true, create(filename, func(file *os.File) error {
+			return lola.Write(file, network)
+		})
+	case ".def":
+		netFilename := strings.TrimSuffix(filename, filepath.Ext(filename)) + ".net"
+		return true, create(filename, func(defFile *os.File) error {
+			return create(netFilename, func(netFile *os.File) error {
+				return greatspn.Write(netFile, defFile, network, composition)
+			})
+		})
+	default:
+		return false, nil
+	}
+}
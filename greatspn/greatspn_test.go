@@ -0,0 +1,147 @@
+package greatspn
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"git.yo2.cz/drahoslav/penego/compose"
+	"git.yo2.cz/drahoslav/penego/draw"
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+const example = `|0|
+comment
+|
+f 1 2 1 3 1 0 0
+M 2 0 0 0
+P1 -1 1 1 0.2 0.2 0
+P2 0 3 1 0.2 0.2 0
+R 0.5 0 0 0
+G1 0 0 2
+T1 -1 1 0 1 0 2 0 0.2 0.2 0.2 -0.2 0
+ 1 1 0 0
+ 2
+ 2 2 1 0
+2 2
+ 1 1 0 0
+ 0
+T2 1 1 127 1 0 2 2 0.2 0.2 0.2 -0.2 0
+ 1 2 0 0
+ 1
+ 1 1 0 0
+ 0
+T3 1 1 1 1 0 4 1 0.2 0.2 0.2 -0.2 0
+ 1 1 0 0
+ 1
+ 1 2 0 0
+ 1
+ 1 1 0 0
+`
+
+func TestParse(test *testing.T) {
+	resNet, composition, err := Parse(strings.NewReader(example))
+	if err != nil {
+		test.Fatalf("Parser failed with %s", err)
+	}
+
+	p1 := &net.Place{Id: "P1", Tokens: 2}
+	p2 := &net.Place{Id: "P2"}
+	refNet := net.New(
+		net.Places{p1, p2},
+		net.Transitions{
+			{Id: "T1", Origins: net.Arcs{{Weight: 1, Place: p1}}, Targets: net.Arcs{{Weight: 2, Place: p2}, {Weight: 1, Place: p1}}},
+			{Id: "T2", Origins: net.Arcs{{Weight: 1, Place: p2}}, Targets: net.Arcs{{Weight: 1, Place: p1}}},
+			{Id: "T3", Priority: 1, Origins: net.Arcs{{Weight: 1, Place: p1}, {Weight: 1, Type: net.InhibitorArc, Place: p1}}, Targets: net.Arcs{{Weight: 1, Place: p2}}},
+		},
+	)
+	if eq, err := resNet.Equals(&refNet); !eq {
+		test.Errorf("Parser failed, because %s \n%s\nshould be\n%s\n", err, resNet, refNet)
+	}
+
+	trans := resNet.Transitions()
+	if str := trans[0].TimeFunc.String(); str != net.GetExponentialTimeFunc(2*time.Second).String() {
+		test.Errorf("T1 should have exponential time, not %s", str)
+	}
+	if str := trans[1].TimeFunc.String(); str != net.GetConstantTimeFunc(time.Second).String() {
+		test.Errorf("T2 should have constant time, not %s", str)
+	}
+	if trans[2].TimeFunc != nil {
+		test.Errorf("T3 should be immediate")
+	}
+
+	places := resNet.Places()
+	poss := composition.PathPositions(trans[0], places[1])
+	if len(poss) != 3 {
+		test.Fatalf("arc from T1 to P2 should have one waypoint, got %v", poss)
+	}
+	pos1, _ := composition.Position(places[0])
+	if poss[1].X-pos1.X != 1*Scale || poss[1].Y-pos1.Y != 1*Scale {
+		test.Errorf("waypoint should be one unit right and down from P1, got %v and %v", poss[1], pos1)
+	}
+}
+
+func TestParseErrors(test *testing.T) {
+	inputs := []string{
+		"P1 0 0 0",                                                // missing header
+		"|0|\n|\nf 0 1 0 0 0 0 0\n",                               // missing place
+		"|0|\n|\nf 0 1 0 0 0 0 0\nP1 -1 0 0",                      // undefined parameter
+		strings.Replace(example, " 2 2 1 0", " 2 3 1 0", 1),       // undefined place
+		strings.Replace(example, "T3 1 1 1", "T3 1 1 5", 1),       // undefined group
+		strings.Replace(example, " 0\nT2", " 1\n 2 1 0 0\nT2", 1), // inhibitor multiplicity
+	}
+	for _, input := range inputs {
+		if _, _, err := Parse(strings.NewReader(input)); err == nil {
+			test.Errorf("Parser should fail on\n%s", input)
+		}
+	}
+}
+
+func TestWriteParse(test *testing.T) {
+	origNet, err := net.Parse(`
+		p (2)
+		q ()
+		----
+		p -> [2s] -> q
+		!q, p -> [p=2] -> 3*q
+		q -> [exp(4s)] -> p
+		q -> [] -> p
+	`)
+	if err != nil {
+		test.Fatalf("net.Parse failed with %s", err)
+	}
+	origComposition := compose.GetSimple(origNet)
+	trans, places := origNet.Transitions(), origNet.Places()
+	origComposition.SetPathPositions(trans[0], places[1], []draw.Pos{{30, 60}})
+	origComposition.CenterTo(0, 0)
+
+	netBuf, defBuf := &bytes.Buffer{}, &bytes.Buffer{}
+	if err := Write(netBuf, defBuf, origNet, origComposition); err != nil {
+		test.Fatalf("Write failed with %s", err)
+	}
+	resNet, resComposition, err := Parse(netBuf)
+	if err != nil {
+		test.Fatalf("Parse of\n%s\nfailed with %s", netBuf, err)
+	}
+	if eq, err := resNet.Equals(&origNet); !eq {
+		test.Errorf("Written net differs, because %s \n%s\nshould be\n%s\n", err, resNet, origNet)
+	}
+	for i, tran := range resNet.Transitions() {
+		if tran.TimeFunc.String() != trans[i].TimeFunc.String() {
+			test.Errorf("time of %d. transition should be %s, not %s", i, trans[i].TimeFunc, tran.TimeFunc)
+		}
+		origPos, _ := origComposition.Position(trans[i])
+		if pos, _ := resComposition.Position(tran); pos != origPos {
+			test.Errorf("position of %d. transition should be %v, not %v", i, origPos, pos)
+		}
+	}
+	poss := resComposition.PathPositions(resNet.Transitions()[0], resNet.Places()[1])
+	origPoss := origComposition.PathPositions(trans[0], places[1])
+	if len(poss) != 3 || poss[1] != origPoss[1] {
+		test.Errorf("waypoints should be %v, not %v", origPoss, poss)
+	}
+	if defBuf.Len() == 0 {
+		test.Errorf(".def file should not be empty")
+	}
+}
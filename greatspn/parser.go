@@ -0,0 +1,344 @@
+// Package greatspn implements parser and writer of GreatSPN .net/.def format
+//
+// Exponential transitions are mapped to exp(1/rate), deterministic to const(rate)
+// and immediate transitions to priority of their group minus one.
+// Uniform and Erlang timing can not be expressed,
+// so such transitions are written as exponential with the same mean.
+// Only the .net file is read, measures of .def file are not supported.
+package greatspn
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.yo2.cz/drahoslav/penego/compose"
+	"git.yo2.cz/drahoslav/penego/draw"
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+// TimeUnit is duration of one unit of time, rates are in 1/TimeUnit
+var TimeUnit = time.Second
+
+// Scale is number of pixels per one unit of GreatSPN coordinates
+var Scale = 60.0
+
+// kinds of transitions
+const (
+	exponential   = 0
+	deterministic = 127
+)
+
+// reads whitespace separated fields of .net file line by line
+type scanner struct {
+	lines  []string
+	lineNo int
+	fields []string
+}
+
+func (s *scanner) nextLine() error {
+	for s.lineNo < len(s.lines) {
+		s.fields = strings.Fields(s.lines[s.lineNo])
+		s.lineNo++
+		if len(s.fields) > 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("unexpected end of file")
+}
+
+// moves to next line and checks it has at least n fields
+func (s *scanner) line(n int) error {
+	if err := s.nextLine(); err != nil {
+		return err
+	}
+	if len(s.fields) < n {
+		return fmt.Errorf("line %d: expected %d fields, got %d", s.lineNo, n, len(s.fields))
+	}
+	return nil
+}
+
+func (s *scanner) int(i int) (int, error) {
+	n, err := strconv.Atoi(s.fields[i])
+	if err != nil {
+		return 0, fmt.Errorf("line %d: invalid number `%s`", s.lineNo, s.fields[i])
+	}
+	return n, nil
+}
+
+func (s *scanner) float(i int) (float64, error) {
+	n, err := strconv.ParseFloat(s.fields[i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("line %d: invalid number `%s`", s.lineNo, s.fields[i])
+	}
+	return n, nil
+}
+
+func (s *scanner) pos(i int) (draw.Pos, error) {
+	x, err := s.float(i)
+	if err != nil {
+		return draw.Pos{}, err
+	}
+	y, err := s.float(i + 1)
+	if err != nil {
+		return draw.Pos{}, err
+	}
+	return draw.Pos{x * Scale, y * Scale}, nil
+}
+
+// Parse reads net and its composition from GreatSPN .net file
+func Parse(netReader io.Reader) (net.Net, compose.Composition, error) {
+	lines := []string{}
+	lineScanner := bufio.NewScanner(netReader)
+	for lineScanner.Scan() {
+		lines = append(lines, lineScanner.Text())
+	}
+	if err := lineScanner.Err(); err != nil {
+		return net.Net{}, compose.New(), err
+	}
+	network, composition, err := parse(&scanner{lines: lines})
+	if err != nil {
+		return net.Net{}, compose.New(), err
+	}
+	composition.CenterTo(0, 0)
+	return network, composition, nil
+}
+
+func parse(s *scanner) (net.Net, compose.Composition, error) {
+	composition := compose.New()
+	fail := func(err error) (net.Net, compose.Composition, error) {
+		return net.Net{}, compose.New(), err
+	}
+
+	// header and comment
+	if err := s.nextLine(); err != nil || s.fields[0] != "|0|" {
+		return fail(fmt.Errorf("not a GreatSPN net, missing `|0|` header"))
+	}
+	for {
+		if err := s.nextLine(); err != nil {
+			return fail(err)
+		}
+		if s.fields[0] == "|" {
+			break
+		}
+	}
+
+	// counts
+	if err := s.line(7); err != nil {
+		return fail(err)
+	}
+	if s.fields[0] != "f" {
+		return fail(fmt.Errorf("line %d: expected `f`", s.lineNo))
+	}
+	counts := make([]int, 6)
+	for i := range counts {
+		n, err := s.int(i + 1)
+		if err != nil {
+			return fail(err)
+		}
+		counts[i] = n
+	}
+	markParCount, placeCount, rateParCount, tranCount, groupCount := counts[0], counts[1], counts[2], counts[3], counts[4]
+
+	// parameters `name value x y ...`
+	params := func(count int) ([]float64, error) {
+		values := []float64{}
+		for i := 0; i < count; i++ {
+			if err := s.line(2); err != nil {
+				return nil, err
+			}
+			value, err := s.float(1)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	}
+	// negative value refers to parameter
+	resolve := func(value float64, params []float64) (float64, error) {
+		if value >= 0 {
+			return value, nil
+		}
+		i := int(-value)
+		if i < 1 || i > len(params) {
+			return 0, fmt.Errorf("line %d: undefined parameter %d", s.lineNo, i)
+		}
+		return params[i-1], nil
+	}
+
+	markPars, err := params(markParCount)
+	if err != nil {
+		return fail(err)
+	}
+
+	// places `name marking x y tagx tagy layers... 0`
+	places := net.Places{}
+	for i := 0; i < placeCount; i++ {
+		if err := s.line(4); err != nil {
+			return fail(err)
+		}
+		marking, err := s.float(1)
+		if err != nil {
+			return fail(err)
+		}
+		if marking, err = resolve(marking, markPars); err != nil {
+			return fail(err)
+		}
+		pos, err := s.pos(2)
+		if err != nil {
+			return fail(err)
+		}
+		place := &net.Place{Id: s.fields[0], Tokens: int(marking)}
+		places.Push(place)
+		composition.Move(place, pos.X, pos.Y)
+	}
+
+	ratePars, err := params(rateParCount)
+	if err != nil {
+		return fail(err)
+	}
+
+	// groups `name x y priority`
+	groupPriorities := []int{}
+	for i := 0; i < groupCount; i++ {
+		if err := s.line(4); err != nil {
+			return fail(err)
+		}
+		priority, err := s.int(3)
+		if err != nil {
+			return fail(err)
+		}
+		groupPriorities = append(groupPriorities, priority)
+	}
+
+	// arcs `multiplicity place points layers... 0` each followed by points `x y`
+	arcs := func(tran *net.Transition, count int, arcs *net.Arcs, inhibitor, out bool) error {
+		for i := 0; i < count; i++ {
+			if err := s.line(3); err != nil {
+				return err
+			}
+			weight, err := s.int(0)
+			if err != nil {
+				return err
+			}
+			p, err := s.int(1)
+			if err != nil {
+				return err
+			}
+			if p < 1 || p > len(places) {
+				return fmt.Errorf("line %d: undefined place %d", s.lineNo, p)
+			}
+			place := places[p-1]
+			pointCount, err := s.int(2)
+			if err != nil {
+				return err
+			}
+			poss := []draw.Pos{}
+			for j := 0; j < pointCount; j++ {
+				if err := s.line(2); err != nil {
+					return err
+				}
+				pos, err := s.pos(0)
+				if err != nil {
+					return err
+				}
+				poss = append(poss, pos)
+			}
+			if weight < 1 {
+				return fmt.Errorf("line %d: multiplicity of arc of `%s` must be positive", s.lineNo, tran.Id)
+			}
+			switch {
+			case inhibitor && weight > 1:
+				return fmt.Errorf("line %d: inhibitor arc of `%s` with multiplicity %d is not supported", s.lineNo, tran.Id, weight)
+			case inhibitor:
+				arcs.PushInhibitor(place)
+			default:
+				arcs.Push(weight, place)
+			}
+			if out {
+				composition.SetPathPositions(tran, place, poss)
+			} else {
+				composition.SetPathPositions(place, tran, poss)
+			}
+		}
+		return nil
+	}
+
+	// transitions `name rate enabling kind inputs orientation x y ...`
+	transitions := net.Transitions{}
+	for i := 0; i < tranCount; i++ {
+		if err := s.line(8); err != nil {
+			return fail(err)
+		}
+		tran := &net.Transition{Id: s.fields[0], Origins: net.Arcs{}, Targets: net.Arcs{}}
+		rate, err := s.float(1)
+		if err != nil {
+			return fail(err)
+		}
+		if rate, err = resolve(rate, ratePars); err != nil {
+			return fail(err)
+		}
+		kind, err := s.int(3)
+		if err != nil {
+			return fail(err)
+		}
+		pos, err := s.pos(6)
+		if err != nil {
+			return fail(err)
+		}
+		switch {
+		case kind == exponential:
+			if rate <= 0 {
+				return fail(fmt.Errorf("line %d: rate of `%s` must be positive", s.lineNo, tran.Id))
+			}
+			tran.TimeFunc = net.GetExponentialTimeFunc(units(1 / rate))
+		case kind == deterministic:
+			tran.TimeFunc = net.GetConstantTimeFunc(units(rate))
+		case kind > 0 && kind <= len(groupPriorities):
+			tran.Priority = groupPriorities[kind-1] - 1
+		default:
+			return fail(fmt.Errorf("line %d: unknown kind %d of `%s`", s.lineNo, kind, tran.Id))
+		}
+		// count of input arcs is part of transition line
+		// counts of output and inhibitor arcs are on separate lines
+		count, err := s.int(4)
+		if err != nil {
+			return fail(err)
+		}
+		if err := arcs(tran, count, &tran.Origins, false, false); err != nil {
+			return fail(err)
+		}
+		if count, err = arcCount(s); err != nil {
+			return fail(err)
+		}
+		if err := arcs(tran, count, &tran.Targets, false, true); err != nil {
+			return fail(err)
+		}
+		if count, err = arcCount(s); err != nil {
+			return fail(err)
+		}
+		if err := arcs(tran, count, &tran.Origins, true, false); err != nil {
+			return fail(err)
+		}
+		tran.EnsureOrigins()
+		transitions.Push(tran)
+		composition.Move(tran, pos.X, pos.Y)
+	}
+
+	return net.New(places, transitions), composition, nil
+}
+
+func arcCount(s *scanner) (int, error) {
+	if err := s.line(1); err != nil {
+		return 0, err
+	}
+	return s.int(0)
+}
+
+func units(n float64) time.Duration {
+	return time.Duration(n * float64(TimeUnit))
+}
@@ -0,0 +1,143 @@
+package greatspn
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.yo2.cz/drahoslav/penego/compose"
+	"git.yo2.cz/drahoslav/penego/draw"
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+func formatFloat(n float64) string {
+	return strconv.FormatFloat(n, 'g', -1, 64)
+}
+
+func formatPos(pos draw.Pos) string {
+	return formatFloat(pos.X/Scale) + " " + formatFloat(pos.Y/Scale)
+}
+
+func inUnits(d time.Duration) float64 {
+	return float64(d) / float64(TimeUnit)
+}
+
+// returns rate and kind of timed transition
+func timing(tran *net.Transition) (float64, int) {
+	name, args := tran.TimeFunc.Definition()
+	switch name {
+	case "const":
+		return inUnits(args[0]), deterministic
+	case "unif":
+		return 1 / inUnits((args[0]+args[1])/2), exponential
+	case "erlang":
+		return 1 / (float64(args[0]) * inUnits(args[1])), exponential
+	default:
+		return 1 / inUnits(args[0]), exponential
+	}
+}
+
+// Write writes net and its composition to GreatSPN .net and .def files
+func Write(netWriter io.Writer, defWriter io.Writer, network net.Net, composition compose.Composition) error {
+	places := network.Places()
+	transitions := network.Transitions()
+	names := transitions.Names()
+
+	placeIndex := map[*net.Place]int{}
+	for i, place := range places {
+		placeIndex[place] = i + 1
+	}
+
+	// each priority level of immediate transitions is a group
+	groupIndex := map[int]int{}
+	priorities := []int{}
+	for _, tran := range transitions {
+		if tran.TimeFunc != nil {
+			continue
+		}
+		if tran.Priority < 0 {
+			return fmt.Errorf("negative priority of transition `%s` can not be expressed", names[tran])
+		}
+		if _, ok := groupIndex[tran.Priority]; !ok {
+			groupIndex[tran.Priority] = 0
+			priorities = append(priorities, tran.Priority)
+		}
+	}
+	sort.Ints(priorities)
+	for i, priority := range priorities {
+		groupIndex[priority] = i + 1
+	}
+
+	position := func(node compose.Composable) draw.Pos {
+		pos, _ := composition.Position(node)
+		return pos
+	}
+
+	lines := []string{"|0|", "|"}
+	lines = append(lines, fmt.Sprintf("f 0 %d 0 %d %d 0 0", len(places), len(transitions), len(priorities)))
+
+	for _, place := range places {
+		lines = append(lines, fmt.Sprintf("%s %d %s 0.25 0.25 0", place.Id, place.Tokens, formatPos(position(place))))
+	}
+
+	for _, priority := range priorities {
+		lines = append(lines, fmt.Sprintf("G%d 0 0 %d", priority+1, priority+1))
+	}
+
+	arcs := func(tran *net.Transition, arcs net.Arcs, inhibitor bool, out bool) []string {
+		arcLines := []string{}
+		for _, arc := range arcs {
+			if arc.Place.Hidden() || (arc.Type == net.InhibitorArc) != inhibitor {
+				continue
+			}
+			var poss []draw.Pos
+			if out {
+				poss = composition.PathPositions(tran, arc.Place)
+			} else {
+				poss = composition.PathPositions(arc.Place, tran)
+			}
+			poss = poss[1 : len(poss)-1]
+			arcLines = append(arcLines, fmt.Sprintf(" %d %d %d 0", arc.Weight, placeIndex[arc.Place], len(poss)))
+			for _, pos := range poss {
+				arcLines = append(arcLines, formatPos(pos))
+			}
+		}
+		return arcLines
+	}
+	count := func(arcLines []string) int {
+		n := 0
+		for _, line := range arcLines {
+			if strings.HasPrefix(line, " ") {
+				n++
+			}
+		}
+		return n
+	}
+
+	for _, tran := range transitions {
+		rate, kind := 1.0, groupIndex[tran.Priority]
+		if tran.TimeFunc != nil {
+			rate, kind = timing(tran)
+		}
+		inputs := arcs(tran, tran.Origins, false, false)
+		outputs := arcs(tran, tran.Targets, false, true)
+		inhibitors := arcs(tran, tran.Origins, true, false)
+		lines = append(lines, fmt.Sprintf("%s %s 0 %d %d 0 %s 0.25 0.25 0.25 -0.25 0",
+			names[tran], formatFloat(rate), kind, count(inputs), formatPos(position(tran)),
+		))
+		lines = append(lines, inputs...)
+		lines = append(lines, fmt.Sprintf(" %d", count(outputs)))
+		lines = append(lines, outputs...)
+		lines = append(lines, fmt.Sprintf(" %d", count(inhibitors)))
+		lines = append(lines, inhibitors...)
+	}
+
+	if _, err := io.WriteString(netWriter, strings.Join(lines, "\n")+"\n"); err != nil {
+		return err
+	}
+	_, err := io.WriteString(defWriter, "|256\n%\n|\n")
+	return err
+}
@@ -31,6 +31,9 @@ const ( //  font awesome corresponding characters
 	CenterOnIcon = '\ue3b4'
 	CenterOffIcon = '\ue3b5'
 	RotateIcon = '\ue1c1'
+
+	EditOffIcon = '\u2610'
+	EditOnIcon  = '\u2611'
 )
 
 func AlwaysIcon(icon Icon) func() Icon {
@@ -0,0 +1,83 @@
+// windows for editing properties of places, transitions and arcs
+
+package gui
+
+import (
+	"math"
+
+	"github.com/andlabs/ui"
+)
+
+var propertiesWindow *ui.Window
+
+// EditNode shows window with description of place or transition and number of tokens of place
+// tokens are not shown if they are negative
+// onApply is called with new values, when they are confirmed
+func EditNode(title, description string, tokens int, onApply func(description string, tokens int)) {
+	ui.QueueMain(func() {
+		box := ui.NewVerticalBox()
+		box.SetPadded(true)
+
+		descriptionInput := ui.NewEntry()
+		descriptionInput.SetText(description)
+		box.Append(line(pair{ui.NewLabel("description"), true}, pair{descriptionInput, false}), false)
+
+		var tokensInput *ui.Spinbox
+		if tokens >= 0 {
+			tokensInput = ui.NewSpinbox(0, math.MaxInt32)
+			tokensInput.SetValue(tokens)
+			box.Append(line(pair{ui.NewLabel("tokens"), true}, pair{tokensInput, false}), false)
+		}
+
+		box.Append(createApplyButton(func() {
+			if tokensInput != nil {
+				tokens = tokensInput.Value()
+			}
+			onApply(descriptionInput.Text(), tokens)
+		}), false)
+		showProperties(title, box)
+	})
+}
+
+// EditArc shows window with weight of arc and whether it is inhibitor arc
+// type of arc can be changed only if canInhibit is true
+// onApply is called with new values, when they are confirmed
+func EditArc(title string, weight int, inhibitor, canInhibit bool, onApply func(weight int, inhibitor bool)) {
+	ui.QueueMain(func() {
+		box := ui.NewVerticalBox()
+		box.SetPadded(true)
+
+		weightInput := ui.NewSpinbox(1, math.MaxInt32)
+		weightInput.SetValue(weight)
+		box.Append(line(pair{ui.NewLabel("weight"), true}, pair{weightInput, false}), false)
+
+		inhibitorInput := ui.NewCheckbox("inhibitor")
+		inhibitorInput.SetChecked(inhibitor)
+		if canInhibit {
+			box.Append(inhibitorInput, false)
+		}
+
+		box.Append(createApplyButton(func() {
+			onApply(weightInput.Value(), inhibitorInput.Checked())
+		}), false)
+		showProperties(title, box)
+	})
+}
+
+// returns button which calls apply and closes window with properties
+func createApplyButton(apply func()) ui.Control {
+	button := ui.NewButton("Apply")
+	button.OnClicked(func(*ui.Button) {
+		apply()
+		toggleWindow(&propertiesWindow, "", nil)
+	})
+	return button
+}
+
+// shows window with properties, instead of the previous one
+func showProperties(title string, box ui.Control) {
+	if propertiesWindow != nil {
+		toggleWindow(&propertiesWindow, "", nil)
+	}
+	toggleWindow(&propertiesWindow, title, box)
+}
@@ -4,6 +4,8 @@ package gui
 // exports Screen
 
 import (
+	"math"
+	"strings"
 	"time"
 
 	"git.yo2.cz/drahoslav/penego/draw"
@@ -16,6 +18,11 @@ var (
 	guiSt *storage.Storage
 )
 
+const (
+	MinZoom = 0.1
+	MaxZoom = 10.0
+)
+
 func init() {
 	guiSt = storage.Of("gui")
 }
@@ -24,6 +31,8 @@ func nameToKey(key string) glfw.Key {
 	switch {
 	case key >= "A" && key <= "Z":
 		return glfw.Key(rune(key[0])-'A') + glfw.KeyA
+	case key >= "0" && key <= "9":
+		return glfw.Key(rune(key[0])-'0') + glfw.Key0
 	case key == "space":
 		return glfw.KeySpace
 	case key == "home":
@@ -32,11 +41,52 @@ func nameToKey(key string) glfw.Key {
 		return glfw.KeyRight
 	case key == "left":
 		return glfw.KeyLeft
+	case key == "escape":
+		return glfw.KeyEscape
+	case key == "delete":
+		return glfw.KeyDelete
+	case key == "backspace":
+		return glfw.KeyBackspace
+	case key == "plus":
+		return glfw.KeyEqual // shares key with +
+	case key == "minus":
+		return glfw.KeyMinus
+	case key == "kpadd":
+		return glfw.KeyKPAdd
+	case key == "kpsubtract":
+		return glfw.KeyKPSubtract
 	default:
 		return glfw.KeyUnknown
 	}
 }
 
+// splits name of key with modifiers, e.g. ctrl+shift+Z, to key and modifiers
+// keys named without modifiers ignore them
+func nameToKeyMods(name string) (glfw.Key, glfw.ModifierKey, bool) {
+	parts := strings.Split(name, "+")
+	mods := glfw.ModifierKey(0)
+	for _, mod := range parts[:len(parts)-1] {
+		switch mod {
+		case "ctrl":
+			mods |= glfw.ModControl
+		case "shift":
+			mods |= glfw.ModShift
+		case "alt":
+			mods |= glfw.ModAlt
+		}
+	}
+	return nameToKey(parts[len(parts)-1]), mods, len(parts) > 1
+}
+
+// tells whether held modifiers match those of key binding
+// binding without modifiers ignores shift only, so e.g. ctrl+C does not trigger C
+func modsMatch(mods, wantedMods glfw.ModifierKey, withMods bool) bool {
+	if !withMods {
+		return mods&(glfw.ModControl|glfw.ModAlt) == 0
+	}
+	return mods&(glfw.ModControl|glfw.ModShift|glfw.ModAlt) == wantedMods
+}
+
 type RedrawFunc func(draw.Drawer)
 
 // Screen provide exported functions for drawing graphic content
@@ -112,18 +162,58 @@ func (s *Screen) setSizeCallback(f func(*Screen, int, int)) {
 
 /* exported methods */
 
+// Reset moves view to the origin and cancels zoom
 func (s *Screen) Reset() {
 	offset := guiSt.Of("offset")
 	offset.Set("x", 0.0)
 	offset.Set("y", 0.0)
+	guiSt.Set("zoom", 1.0)
 	s.newCtx()
 }
 
 func (s *Screen) Pan(dx, dy float64) {
-	s.ctx.Translate(dx, dy)
 	offset := guiSt.Of("offset")
 	offset.AddFloat("x", -dx)
 	offset.AddFloat("y", -dy)
+	draw.Init(s.ctx, s.width, s.height)
+}
+
+// ZoomAt multiplies zoom by factor, so that point x, y stays at the same place of screen
+// zoom is kept between MinZoom and MaxZoom
+func (s *Screen) ZoomAt(factor, x, y float64) {
+	zoom := draw.Zoom()
+	newZoom := math.Max(MinZoom, math.Min(MaxZoom, zoom*factor))
+	factor = newZoom / zoom
+	offset := guiSt.Of("offset")
+	offset.Set("x", x-(x-offset.Float("x"))/factor)
+	offset.Set("y", y-(y-offset.Float("y"))/factor)
+	guiSt.Set("zoom", newZoom)
+	draw.Init(s.ctx, s.width, s.height)
+}
+
+// Zoom multiplies zoom by factor around the center of screen
+func (s *Screen) Zoom(factor float64) {
+	s.ZoomAt(factor, guiSt.Float("offset.x"), guiSt.Float("offset.y"))
+}
+
+// Fit zooms and pans view, so that the whole given rectangle is visible between menus
+// with a margin, it is never zoomed in more than to 100 %
+func (s *Screen) Fit(left, top, right, bottom float64) {
+	const margin = 16.0
+	width := float64(s.width) - 2*margin
+	height := float64(s.height) - 2*draw.MENU_HEIGHT - 2*margin
+	zoom := 1.0
+	if right > left {
+		zoom = math.Min(zoom, width/(right-left))
+	}
+	if bottom > top {
+		zoom = math.Min(zoom, height/(bottom-top))
+	}
+	offset := guiSt.Of("offset")
+	offset.Set("x", (left+right)/2)
+	offset.Set("y", (top+bottom)/2)
+	guiSt.Set("zoom", math.Max(MinZoom, zoom))
+	draw.Init(s.ctx, s.width, s.height)
 }
 
 func (s *Screen) ForceRedraw(block bool) {
@@ -178,6 +268,30 @@ func (s *Screen) DrawTransition(pos draw.Pos, attrs, description string) {
 	}
 }
 
+func (s *Screen) DrawLabel(pos draw.Pos, text string) {
+	if s.ctx != nil {
+		draw.Label(s.ctx, s.style, pos, text)
+	}
+}
+
+func (s *Screen) DrawTransitionState(pos draw.Pos, enabled bool, progress float64) {
+	if s.ctx != nil {
+		draw.TransitionState(s.ctx, pos, enabled, progress)
+	}
+}
+
+func (s *Screen) DrawToken(pos draw.Pos, n int) {
+	if s.ctx != nil {
+		draw.Token(s.ctx, s.style, pos, n)
+	}
+}
+
+func (s *Screen) DrawLegend(pos draw.Pos, title, min, max string) {
+	if s.ctx != nil {
+		draw.Legend(s.ctx, s.style, pos, title, min, max)
+	}
+}
+
 func (s *Screen) DrawInArc(path []draw.Pos, weight int) {
 	if s.ctx != nil {
 		draw.Arc(s.ctx, s.style, path, draw.In, weight)
@@ -196,10 +310,21 @@ func (s *Screen) DrawInhibitorArc(path []draw.Pos) {
 	}
 }
 
+// DrawSelectionBox draws rectangle of selection between two corners
+func (s *Screen) DrawSelectionBox(from, to draw.Pos) {
+	if s.ctx != nil {
+		draw.SelectionBox(s.ctx, from, to)
+	}
+}
+
+// OnKey registers callback called when key is pressed
+// keyName can be prefixed by modifiers, e.g. ctrl+shift+Z, which then have to be held (and no others)
+// key named without modifiers is not triggered when ctrl or alt is held
 func (s *Screen) OnKey(keyName string, cb func()) {
 	var prevcb glfw.KeyCallback
+	wantedKey, wantedMods, withMods := nameToKeyMods(keyName)
 	prevcb = s.Window.SetKeyCallback(func(w *glfw.Window, key glfw.Key, scanCode int, action glfw.Action, mods glfw.ModifierKey) {
-		if action == glfw.Press && nameToKey(keyName) == key {
+		if action == glfw.Press && key != glfw.KeyUnknown && wantedKey == key && modsMatch(mods, wantedMods, withMods) {
 			doInLoop(cb, false)
 		}
 		if prevcb != nil {
@@ -231,14 +356,39 @@ func (s *Screen) RegisterControl(which int, key string, getIcon func() Icon, lab
 
 func (s *Screen) normalize(x, y float64, centered bool) (float64, float64) {
 	if centered {
-		x -= float64(s.width) / 2
-		y -= float64(s.height) / 2
+		zoom := draw.Zoom()
+		x = (x - float64(s.width)/2) / zoom
+		y = (y - float64(s.height)/2) / zoom
 		x += guiSt.Float("offset.x")
 		y += guiSt.Float("offset.y")
 	}
 	return x, y
 }
 
+// CursorPos returns current position of mouse cursor
+func (s *Screen) CursorPos(centered bool) (float64, float64) {
+	x, y := s.GetCursorPos()
+	return s.normalize(x, y, centered)
+}
+
+// ShiftHeld tells whether any shift key is held down
+func (s *Screen) ShiftHeld() bool {
+	return s.GetKey(glfw.KeyLeftShift) == glfw.Press || s.GetKey(glfw.KeyRightShift) == glfw.Press
+}
+
+// OnScroll registers callback called when mouse wheel is scrolled, with position of cursor
+// delta is positive when scrolled up
+func (s *Screen) OnScroll(centered bool, cb func(x, y, delta float64)) {
+	var prevcb glfw.ScrollCallback
+	prevcb = s.Window.SetScrollCallback(func(w *glfw.Window, xoff, yoff float64) {
+		if prevcb != nil {
+			prevcb(w, xoff, yoff)
+		}
+		x, y := s.CursorPos(centered)
+		cb(x, y, yoff)
+	})
+}
+
 func (s *Screen) OnMouseMove(centered bool, cb func(float64, float64) bool) {
 	var prevcb glfw.CursorPosCallback
 	prevcb = s.Window.SetCursorPosCallback(func(w *glfw.Window, x float64, y float64) {
@@ -255,6 +405,57 @@ func (s *Screen) OnMouseMove(centered bool, cb func(float64, float64) bool) {
 	})
 }
 
+// OnDoubleClick registers callback called when left mouse button is clicked twice in short time
+func (s *Screen) OnDoubleClick(centered bool, cb func(x, y float64)) {
+	var prevClickCb glfw.MouseButtonCallback
+
+	const interval = 400 * time.Millisecond
+	const distance = 4.0
+	lastX, lastY := 0.0, 0.0
+	lastTime := time.Time{}
+
+	prevClickCb = s.Window.SetMouseButtonCallback(func(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mod glfw.ModifierKey) {
+		if prevClickCb != nil {
+			prevClickCb(w, button, action, mod)
+		}
+		if button == glfw.MouseButtonLeft && action == glfw.Release {
+			x, y := w.GetCursorPos()
+			x, y = s.normalize(x, y, centered)
+			now := time.Now()
+			if now.Sub(lastTime) < interval && math.Abs(x-lastX) < distance && math.Abs(y-lastY) < distance {
+				cb(x, y)
+				now = time.Time{} // third click does not make another double click
+			}
+			lastX, lastY, lastTime = x, y, now
+		}
+	})
+}
+
+// OnShiftClick registers callback called when left mouse button is clicked while shift is held
+func (s *Screen) OnShiftClick(centered bool, cb func(x, y float64)) {
+	var prevClickCb glfw.MouseButtonCallback
+
+	const distance = 4.0
+	pressX, pressY := 0.0, 0.0
+
+	prevClickCb = s.Window.SetMouseButtonCallback(func(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mod glfw.ModifierKey) {
+		if prevClickCb != nil {
+			prevClickCb(w, button, action, mod)
+		}
+		if button != glfw.MouseButtonLeft || mod&glfw.ModShift == 0 {
+			return
+		}
+		x, y := w.GetCursorPos()
+		x, y = s.normalize(x, y, centered)
+		if action == glfw.Press {
+			pressX, pressY = x, y
+		}
+		if action == glfw.Release && math.Abs(x-pressX) < distance && math.Abs(y-pressY) < distance {
+			cb(x, y)
+		}
+	})
+}
+
 func (s *Screen) OnDrag(centered bool, cb func(x, y, deltax, deltaY, startX, startY float64, done bool)) {
 	var prevClickCb glfw.MouseButtonCallback
 	var prevCurPosCb glfw.CursorPosCallback
@@ -0,0 +1,46 @@
+package gui
+
+import (
+	"testing"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+func TestNameToKeyMods(test *testing.T) {
+	cases := []struct {
+		name     string
+		key      glfw.Key
+		mods     glfw.ModifierKey
+		withMods bool
+	}{
+		{"C", glfw.KeyC, 0, false},
+		{"ctrl+C", glfw.KeyC, glfw.ModControl, true},
+		{"ctrl+shift+Z", glfw.KeyZ, glfw.ModControl | glfw.ModShift, true},
+		{"space", glfw.KeySpace, 0, false},
+		{"kpadd", glfw.KeyKPAdd, 0, false},
+		{"ctrl+kpsubtract", glfw.KeyKPSubtract, glfw.ModControl, true},
+	}
+	for _, c := range cases {
+		key, mods, withMods := nameToKeyMods(c.name)
+		if key != c.key || mods != c.mods || withMods != c.withMods {
+			test.Errorf("%s should be %v %v %v, got %v %v %v", c.name, c.key, c.mods, c.withMods, key, mods, withMods)
+		}
+	}
+}
+
+func TestModsMatch(test *testing.T) {
+	_, plainMods, plain := nameToKeyMods("C")
+	_, ctrlMods, ctrl := nameToKeyMods("ctrl+C")
+	if !modsMatch(0, plainMods, plain) || !modsMatch(glfw.ModShift, plainMods, plain) {
+		test.Errorf("plain binding should match without modifiers and with shift")
+	}
+	if modsMatch(glfw.ModControl, plainMods, plain) || modsMatch(glfw.ModAlt, plainMods, plain) {
+		test.Errorf("plain binding should not match with ctrl or alt")
+	}
+	if !modsMatch(glfw.ModControl, ctrlMods, ctrl) {
+		test.Errorf("ctrl binding should match with ctrl")
+	}
+	if modsMatch(glfw.ModControl|glfw.ModShift, ctrlMods, ctrl) || modsMatch(0, ctrlMods, ctrl) {
+		test.Errorf("ctrl binding should match only with ctrl")
+	}
+}
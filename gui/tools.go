@@ -66,6 +66,7 @@ func createExportBox() ui.Control {
 	tab.Append("SVG", createFormatPresets("svg"))
 	tab.Append("PNG", createFormatPresets("png"))
 	tab.Append("PDF", createFormatPresets("pdf"))
+	tab.Append("TikZ", createFormatPresets("tex"))
 
 	return tab
 }
@@ -181,9 +182,19 @@ func createFormatPresets(ext string) ui.Control {
 		box.Append(orientation(), false)
 		box.Append(paperFormat(), false)
 	}
-	box.Append(createIntInput("width", 1, math.MaxInt32), false)
-	box.Append(createIntInput("height", 1, math.MaxInt32), false)
-	box.Append(createIntInput("zoom", -5, +5), false)
+	if ext != "tex" { // tikz picture has no fixed size
+		box.Append(createIntInput("width", 1, math.MaxInt32), false)
+		box.Append(createIntInput("height", 1, math.MaxInt32), false)
+		box.Append(createIntInput("zoom", -5, +5), false)
+		box.Append(createBoolInput("fit"), false)
+		box.Append(createIntInput("margin", 0, 1000), false)
+	}
+	if ext == "png" {
+		box.Append(createIntInput("dpi", 24, 1200), false)
+	}
+	if ext == "pdf" {
+		box.Append(createBoolInput("multipage"), false)
+	}
 	box.Append(createExportAs(ext), false)
 	box.Append(progressBar, true)
 	box.Append(button, true)
@@ -224,6 +235,15 @@ func createIntInput(name string, min, max int) ui.Control {
 	return line(pair{label, true}, pair{input, false})
 }
 
+func createBoolInput(name string) ui.Control {
+	checkbox := ui.NewCheckbox(name)
+	checkbox.SetChecked(exportSt.Bool(name))
+	checkbox.OnToggled(func(*ui.Checkbox) {
+		exportSt.Set(name, checkbox.Checked())
+	})
+	return checkbox
+}
+
 func createFloatInput(name string, min, max int) ui.Control {
 	label := ui.NewLabel(name)
 
@@ -258,6 +278,25 @@ func createRadioInput(name string, options ...string) ui.Control {
 	return line(buttons...)
 }
 
+// creates line of buttons, each of them sets setting of given name to its command
+// options are pairs of command and key which does the same, it is shown on button
+func createCommandInput(name string, options ...[2]string) ui.Control {
+	label := ui.NewLabel(name)
+
+	buttons := []pair{pair{label, true}}
+
+	for _, opt := range options {
+		command := opt[0]
+		btn := ui.NewButton(command + " (" + opt[1] + ")")
+		btn.OnClicked(func(*ui.Button) {
+			settingsSt.Set(name, command)
+		})
+		buttons = append(buttons, pair{btn, false})
+	}
+
+	return line(buttons...)
+}
+
 func IsExportOn() bool {
 	return exportWindow != nil
 }
@@ -275,14 +314,31 @@ func createSettingsBox() ui.Control {
 	tab := ui.NewTab()
 
 	linewidth := createFloatInput("linewidth", 1, 4)
-	composer := createRadioInput("composer", "simple", "complex")
+	grid := createFloatInput("grid", 1, 90)
+	tokendots := createFloatInput("tokendots", 0, 20)
+	composer := createRadioInput("composer", "simple", "complex", "force", "orthogonal")
+	theme := createRadioInput("theme", "light", "dark", "contrast")
+	heatmap := createRadioInput("heatmap", "off", "tokens", "occupancy")
 
 	general := ui.NewVerticalBox()
 	general.Append(linewidth, false)
+	general.Append(grid, false)
+	general.Append(tokendots, false)
 	general.Append(composer, false)
+	general.Append(theme, false)
+	general.Append(heatmap, false)
 
 	tab.Append("general", general)
 
+	// commands applied to selected nodes, or to whole net in case of mirroring
+	arrange := ui.NewVerticalBox()
+	arrange.Append(createCommandInput("align", [2]string{"left", "1"}, [2]string{"center", "2"}, [2]string{"right", "3"}), false)
+	arrange.Append(createCommandInput("align", [2]string{"top", "4"}, [2]string{"middle", "5"}, [2]string{"bottom", "6"}), false)
+	arrange.Append(createCommandInput("distribute", [2]string{"horizontally", "7"}, [2]string{"vertically", "8"}), false)
+	arrange.Append(createCommandInput("mirror", [2]string{"horizontally", "H"}, [2]string{"vertically", "V"}), false)
+
+	tab.Append("arrange", arrange)
+
 	// tab.Append("place", nil)
 	// tab.Append("transition", nil)
 	// tab.Append("arc", nil)
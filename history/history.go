@@ -0,0 +1,92 @@
+// Package history keeps commands done by user, so they can be undone and redone
+package history
+
+// Command is change, which can be undone and done again
+type Command interface {
+	Do()
+	Undo()
+}
+
+type command struct {
+	do   func()
+	undo func()
+}
+
+func (cmd command) Do() {
+	cmd.do()
+}
+
+func (cmd command) Undo() {
+	cmd.undo()
+}
+
+// NewCommand returns command made of functions doing and undoing the change
+func NewCommand(do, undo func()) Command {
+	return command{do, undo}
+}
+
+// History is list of done commands and commands undone since the last new command
+// only limited number of the most recent commands is kept
+type History struct {
+	done   []Command
+	undone []Command
+	limit  int
+}
+
+func New(limit int) *History {
+	return &History{[]Command{}, []Command{}, limit}
+}
+
+// Do does command and adds it to history
+func (h *History) Do(cmd Command) {
+	cmd.Do()
+	h.Add(cmd)
+}
+
+// Add adds command, which has already been done, to history
+// commands undone before can not be redone anymore
+func (h *History) Add(cmd Command) {
+	h.done = append(h.done, cmd)
+	if len(h.done) > h.limit {
+		h.done = append(h.done[:0:0], h.done[len(h.done)-h.limit:]...) // forget the oldest
+	}
+	h.undone = h.undone[:0]
+}
+
+// Undo undoes the last done command, returns false if there is none
+func (h *History) Undo() bool {
+	if len(h.done) == 0 {
+		return false
+	}
+	cmd := h.done[len(h.done)-1]
+	h.done = h.done[:len(h.done)-1]
+	cmd.Undo()
+	h.undone = append(h.undone, cmd)
+	return true
+}
+
+// Redo does again the last undone command, returns false if there is none
+func (h *History) Redo() bool {
+	if len(h.undone) == 0 {
+		return false
+	}
+	cmd := h.undone[len(h.undone)-1]
+	h.undone = h.undone[:len(h.undone)-1]
+	cmd.Do()
+	h.done = append(h.done, cmd)
+	return true
+}
+
+func (h *History) CanUndo() bool {
+	return len(h.done) > 0
+}
+
+func (h *History) CanRedo() bool {
+	return len(h.undone) > 0
+}
+
+// Clear forgets all commands
+func (h *History) Clear() {
+	h.done = h.done[:0]
+	h.undone = h.undone[:0]
+}
@@ -0,0 +1,39 @@
+package history
+
+import (
+	"testing"
+)
+
+func TestHistory(test *testing.T) {
+	value := 0
+	add := func(n int) Command {
+		return NewCommand(func() { value += n }, func() { value -= n })
+	}
+	h := New(2)
+	h.Do(add(1))
+	h.Do(add(10))
+	h.Do(add(100))
+	if value != 111 {
+		test.Errorf("commands should be done, got %d", value)
+	}
+
+	for h.Undo() {
+	}
+	if value != 1 {
+		test.Errorf("only 2 commands should be undone, got %d", value)
+	}
+	if !h.Redo() || value != 11 || !h.CanRedo() {
+		test.Errorf("command should be redone, got %d", value)
+	}
+
+	value += 1000
+	h.Add(add(1000))
+	if h.CanRedo() {
+		test.Errorf("undone commands should be forgotten after new command")
+	}
+	h.Undo()
+	h.Undo()
+	if value != 1 || h.CanUndo() {
+		test.Errorf("commands should be undone, got %d", value)
+	}
+}
@@ -0,0 +1,98 @@
+package lola
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+func TestParse(test *testing.T) {
+	resNet, err := Parse(strings.NewReader(`
+		{ example net }
+		PLACE
+		  SAFE 1: p1, p2;
+		  p3;
+		MARKING p1: 2, p3;
+		TRANSITION t1 STRONG FAIR
+		  CONSUME p1: 1, p3: 1; /* comment */
+		  PRODUCE p2: 2;
+		TRANSITION t2
+		  CONSUME ;
+		  PRODUCE p1; // comment
+	`))
+	if err != nil {
+		test.Fatalf("Parser failed with %s", err)
+	}
+
+	p1 := &net.Place{Id: "p1", Tokens: 2}
+	p2 := &net.Place{Id: "p2"}
+	p3 := &net.Place{Id: "p3", Tokens: 1}
+	self := &net.Place{Id: ".", Tokens: 1}
+	refNet := net.New(
+		net.Places{p1, p2, p3},
+		net.Transitions{
+			{Id: "t1", Origins: net.Arcs{{Weight: 1, Place: p1}, {Weight: 1, Place: p3}}, Targets: net.Arcs{{Weight: 2, Place: p2}}},
+			{Id: "t2", Origins: net.Arcs{{Weight: 1, Place: self}}, Targets: net.Arcs{{Weight: 1, Place: p1}, {Weight: 1, Place: self}}},
+		},
+	)
+	if eq, err := resNet.Equals(&refNet); !eq {
+		test.Errorf("Parser failed, because %s \n%s\nshould be\n%s\n", err, resNet, refNet)
+	}
+}
+
+func TestParseErrors(test *testing.T) {
+	inputs := []string{
+		"PLACE p; MARKING q: 1;",                                      // undefined place
+		"PLACE p, p; MARKING ;",                                       // duplicate place
+		"PLACE p; MARKING ; TRANSITION t CONSUME p: 1;",               // missing PRODUCE
+		"PLACE p; MARKING ; TRANSITION t CONSUME p: x; PRODUCE ;",     // invalid weight
+		"PLACE p; MARKING ; { unterminated",                           // comment
+		"PLACE p; MARKING ; TRANSITION t GUARD p CONSUME ; PRODUCE ;", // unknown keyword
+	}
+	for _, input := range inputs {
+		if _, err := Parse(strings.NewReader(input)); err == nil {
+			test.Errorf("Parser should fail on `%s`", input)
+		}
+	}
+}
+
+func TestWriteParse(test *testing.T) {
+	origNet, err := net.Parse(`
+		p (2)
+		q ()
+		----
+		p -> [2s] -> 3*q
+		q -> [] "back" -> p
+		[] -> p
+	`)
+	if err != nil {
+		test.Fatalf("net.Parse failed with %s", err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Write(buf, origNet); err != nil {
+		test.Fatalf("Write failed with %s", err)
+	}
+	resNet, err := Parse(buf)
+	if err != nil {
+		test.Fatalf("Parse of\n%s\nfailed with %s", buf, err)
+	}
+	// descriptions are not preserved
+	origNet.Transitions()[1].Description = ""
+	if eq, err := resNet.Equals(&origNet); !eq {
+		test.Errorf("Written net differs, because %s \n%s\nshould be\n%s\n", err, resNet, origNet)
+	}
+}
+
+func TestWriteInhibitor(test *testing.T) {
+	network, _ := net.Parse(`
+		p ()
+		----
+		!p -> [] -> p
+	`)
+	if err := Write(&bytes.Buffer{}, network); err == nil {
+		test.Errorf("Write should fail on inhibitor arc")
+	}
+}
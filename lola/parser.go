@@ -0,0 +1,237 @@
+// Package lola implements parser and writer of low level net format of LoLA model checker
+// http://service-technology.org/lola/
+//
+// LoLA knows neither time nor priorities, so those are not preserved.
+// Inhibitor arcs can not be expressed either, Write fails on nets containing them.
+// Bounds of places (SAFE n) are ignored on import.
+package lola
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+// splits input to names, numbers, keywords and `,;:` separators
+// comments {...}, /* ... */ and // ... are skipped
+func tokenize(input string) ([]string, error) {
+	tokens := []string{}
+	for i := 0; i < len(input); {
+		switch c := input[i]; {
+		case strings.IndexByte(" \t\r\n", c) >= 0:
+			i++
+		case c == '{':
+			end := strings.IndexByte(input[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += end + 1
+		case strings.HasPrefix(input[i:], "/*"):
+			end := strings.Index(input[i:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += end + 2
+		case strings.HasPrefix(input[i:], "//"):
+			end := strings.IndexByte(input[i:], '\n')
+			if end < 0 {
+				end = len(input) - i
+			}
+			i += end
+		case strings.IndexByte(",;:", c) >= 0:
+			tokens = append(tokens, input[i:i+1])
+			i++
+		default:
+			j := i
+			for ; j < len(input) && strings.IndexByte(" \t\r\n,;:{}()", input[j]) < 0 && !strings.HasPrefix(input[j:], "/*"); j++ {
+			}
+			if j == i {
+				return nil, fmt.Errorf("unexpected `%c`", c)
+			}
+			tokens = append(tokens, input[i:j])
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []string
+}
+
+func (p *parser) peek() string {
+	if len(p.tokens) == 0 {
+		return ""
+	}
+	return p.tokens[0]
+}
+
+func (p *parser) next() string {
+	token := p.peek()
+	if len(p.tokens) > 0 {
+		p.tokens = p.tokens[1:]
+	}
+	return token
+}
+
+func (p *parser) expect(token string) error {
+	if got := p.next(); got != token {
+		if got == "" {
+			got = "end of file"
+		}
+		return fmt.Errorf("expected `%s`, got `%s`", token, got)
+	}
+	return nil
+}
+
+func (p *parser) number() (int, error) {
+	token := p.next()
+	n, err := strconv.Atoi(token)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid number `%s`", token)
+	}
+	return n, nil
+}
+
+// parses `name [: n], name [: n] ...;` list
+// missing number means 1
+func (p *parser) list(item func(name string, n int) error) error {
+	for p.peek() != ";" {
+		name := p.next()
+		if name == "" {
+			return p.expect(";")
+		}
+		n := 1
+		if p.peek() == ":" {
+			p.next()
+			var err error
+			if n, err = p.number(); err != nil {
+				return err
+			}
+		}
+		if err := item(name, n); err != nil {
+			return err
+		}
+		if p.peek() != ";" {
+			if err := p.expect(","); err != nil {
+				return err
+			}
+		}
+	}
+	return p.expect(";")
+}
+
+// Parse reads net in LoLA format
+func Parse(reader io.Reader) (net.Net, error) {
+	content, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return net.Net{}, err
+	}
+	tokens, err := tokenize(string(content))
+	if err != nil {
+		return net.Net{}, err
+	}
+	p := &parser{tokens}
+
+	places := net.Places{}
+	transitions := net.Transitions{}
+	placeById := map[string]*net.Place{}
+
+	getPlace := func(name string) (*net.Place, error) {
+		place, ok := placeById[name]
+		if !ok {
+			return nil, fmt.Errorf("undefined place `%s`", name)
+		}
+		return place, nil
+	}
+
+	parse := func() error {
+		if err := p.expect("PLACE"); err != nil {
+			return err
+		}
+		for p.peek() != "MARKING" {
+			if p.peek() == "SAFE" {
+				p.next()
+				if _, err := p.number(); err != nil {
+					return err
+				}
+				if err := p.expect(":"); err != nil {
+					return err
+				}
+			}
+			err := p.list(func(name string, n int) error {
+				if _, exists := placeById[name]; exists {
+					return fmt.Errorf("place `%s` is already defined", name)
+				}
+				place := &net.Place{Id: name}
+				placeById[name] = place
+				places.Push(place)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		p.next() // MARKING
+		err := p.list(func(name string, n int) error {
+			place, err := getPlace(name)
+			if err == nil {
+				place.Tokens += n
+			}
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		for p.peek() != "" {
+			if err := p.expect("TRANSITION"); err != nil {
+				return err
+			}
+			tran := &net.Transition{Id: p.next(), Origins: net.Arcs{}, Targets: net.Arcs{}}
+			if tran.Id == "" {
+				return fmt.Errorf("missing name of transition")
+			}
+			if p.peek() == "STRONG" || p.peek() == "WEAK" {
+				p.next()
+				if err := p.expect("FAIR"); err != nil {
+					return err
+				}
+			}
+			arcs := func(arcs *net.Arcs) func(string, int) error {
+				return func(name string, n int) error {
+					place, err := getPlace(name)
+					if err == nil && n > 0 {
+						arcs.Push(n, place)
+					}
+					return err
+				}
+			}
+			if err := p.expect("CONSUME"); err != nil {
+				return err
+			}
+			if err := p.list(arcs(&tran.Origins)); err != nil {
+				return fmt.Errorf("transition `%s`: %s", tran.Id, err)
+			}
+			if err := p.expect("PRODUCE"); err != nil {
+				return err
+			}
+			if err := p.list(arcs(&tran.Targets)); err != nil {
+				return fmt.Errorf("transition `%s`: %s", tran.Id, err)
+			}
+			tran.EnsureOrigins()
+			transitions.Push(tran)
+		}
+		return nil
+	}
+
+	if err := parse(); err != nil {
+		return net.Net{}, err
+	}
+	return net.New(places, transitions), nil
+}
@@ -0,0 +1,70 @@
+package lola
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+func arcs(arcs net.Arcs) string {
+	strs := []string{}
+	for _, arc := range arcs {
+		if arc.Place.Hidden() {
+			continue
+		}
+		strs = append(strs, fmt.Sprintf("%s: %d", arc.Place.Id, arc.Weight))
+	}
+	return strings.Join(strs, ", ")
+}
+
+// Write writes net in LoLA format
+// descriptions are kept as comments
+func Write(writer io.Writer, network net.Net) error {
+	transitions := network.Transitions()
+	for _, tran := range transitions {
+		for _, arc := range tran.Origins {
+			if arc.Type == net.InhibitorArc {
+				return fmt.Errorf("inhibitor arc from `%s` can not be expressed in LoLA", arc.Place.Id)
+			}
+		}
+	}
+
+	comment := func(description string) string {
+		if description == "" {
+			return ""
+		}
+		return " { " + strings.Replace(description, "}", ")", -1) + " }"
+	}
+
+	lines := []string{"PLACE"}
+	marking := []string{}
+	places := network.Places()
+	for i, place := range places {
+		sep := ","
+		if i == len(places)-1 {
+			sep = ";"
+		}
+		lines = append(lines, "  "+place.Id+sep+comment(place.Description))
+		if place.Tokens > 0 {
+			marking = append(marking, fmt.Sprintf("%s: %d", place.Id, place.Tokens))
+		}
+	}
+	if len(places) == 0 {
+		lines = append(lines, "  ;")
+	}
+	lines = append(lines, "", "MARKING "+strings.Join(marking, ", ")+";", "")
+
+	names := transitions.Names()
+	for _, tran := range transitions {
+		lines = append(lines,
+			"TRANSITION "+names[tran]+comment(tran.Description),
+			"  CONSUME "+arcs(tran.Origins)+";",
+			"  PRODUCE "+arcs(tran.Targets)+";",
+		)
+	}
+
+	_, err := io.WriteString(writer, strings.Join(lines, "\n")+"\n")
+	return err
+}
@@ -0,0 +1,240 @@
+package net
+
+import (
+	"strconv"
+	"strings"
+)
+
+// FreeId returns id made of prefix and number, which is not used by any place or transition
+func (net *Net) FreeId(prefix string) string {
+	used := map[string]bool{}
+	for _, place := range net.places {
+		used[place.Id] = true
+	}
+	for _, tran := range net.transitions {
+		used[tran.Id] = true
+	}
+	for n := 1; ; n++ {
+		if id := prefix + strconv.Itoa(n); !used[id] {
+			return id
+		}
+	}
+}
+
+func (net *Net) AddPlace(place *Place) {
+	net.places.Push(place)
+}
+
+func (net *Net) AddTransition(tran *Transition) {
+	tran.EnsureOrigins()
+	net.transitions.Push(tran)
+}
+
+// RemovePlace removes place along with all its arcs
+func (net *Net) RemovePlace(place *Place) {
+	for i, p := range net.places {
+		if p == place {
+			net.places = append(net.places[:i:i], net.places[i+1:]...)
+			break
+		}
+	}
+	for _, tran := range net.transitions {
+		tran.Origins.remove(place)
+		tran.Targets.remove(place)
+		tran.EnsureOrigins()
+	}
+}
+
+func (net *Net) RemoveTransition(tran *Transition) {
+	for i, t := range net.transitions {
+		if t == tran {
+			net.transitions.Remove(i)
+			return
+		}
+	}
+}
+
+// Arc returns arc between place and transition, output arc leads from transition to place
+// nil if there is none
+func (net *Net) Arc(place *Place, tran *Transition, output bool) *Arc {
+	arcs := tran.Origins
+	if output {
+		arcs = tran.Targets
+	}
+	for _, arc := range arcs {
+		if arc.Place == place {
+			return arc
+		}
+	}
+	return nil
+}
+
+// SetArc adds or changes arc between place and transition, zero weight removes it
+// inhibitor arc can only lead from place to transition and its weight is always 1
+// self loop of hidden place is removed when first origin is added and restored when last one is removed
+func (net *Net) SetArc(place *Place, tran *Transition, output bool, weight int, arcType ArcType) {
+	arcs := &tran.Origins
+	if output {
+		arcs = &tran.Targets
+		arcType = NormalArc
+	}
+	if arcType == InhibitorArc {
+		weight = 1
+	}
+	if weight <= 0 {
+		arcs.remove(place)
+		tran.EnsureOrigins()
+		return
+	}
+	if arc := net.Arc(place, tran, output); arc != nil {
+		arc.Weight, arc.Type = weight, arcType
+		return
+	}
+	if !output && tran.Origins.IsEmpty() && len(tran.Origins) == 1 { // drop self loop
+		hidden := tran.Origins[0].Place
+		tran.Origins.remove(hidden)
+		tran.Targets.remove(hidden)
+	}
+	*arcs = append(*arcs, &Arc{weight, arcType, place})
+}
+
+// removes arc leading from or to place
+func (arcs *Arcs) remove(place *Place) {
+	for i, arc := range *arcs {
+		if arc.Place == place {
+			*arcs = append((*arcs)[:i:i], (*arcs)[i+1:]...)
+			return
+		}
+	}
+}
+
+// Snapshot is state of net, which can be restored later
+type Snapshot struct {
+	places      Places
+	transitions Transitions
+	placeStates map[*Place]Place
+	tranStates  map[*Transition]Transition
+}
+
+// Snapshot returns copy of places and transitions of net, including their arcs
+func (net *Net) Snapshot() Snapshot {
+	snapshot := Snapshot{
+		append(Places{}, net.places...),
+		append(Transitions{}, net.transitions...),
+		make(map[*Place]Place, len(net.places)),
+		make(map[*Transition]Transition, len(net.transitions)),
+	}
+	for _, place := range net.places {
+		snapshot.placeStates[place] = *place
+	}
+	for _, tran := range net.transitions {
+		state := *tran
+		state.Origins, state.Targets = tran.Origins.copy(), tran.Targets.copy()
+		snapshot.tranStates[tran] = state
+	}
+	return snapshot
+}
+
+// Restore sets net to state of snapshot, places and transitions are the same as when it was taken
+func (net *Net) Restore(snapshot Snapshot) {
+	net.places = append(Places{}, snapshot.places...)
+	net.transitions = append(Transitions{}, snapshot.transitions...)
+	for place, state := range snapshot.placeStates {
+		*place = state
+	}
+	for tran, state := range snapshot.tranStates {
+		*tran = state
+		tran.Origins, tran.Targets = state.Origins.copy(), state.Targets.copy()
+	}
+}
+
+func (arcs Arcs) copy() Arcs {
+	copied := make(Arcs, len(arcs))
+	for i, arc := range arcs {
+		arcCopy := *arc
+		copied[i] = &arcCopy
+	}
+	return copied
+}
+
+// Subnet is copy of some places and transitions along with arcs between them
+// it does not belong to any net, so it can be added to one, even more times
+type Subnet struct {
+	places      Places
+	transitions Transitions
+}
+
+// NewSubnet copies places and transitions, arcs leading to places which are not copied are left out
+func NewSubnet(places Places, transitions Transitions) Subnet {
+	subnet := Subnet{}
+	copies := map[*Place]*Place{}
+	for _, place := range places {
+		placeCopy := *place
+		placeCopy.line, placeCopy.parsed = 0, ""
+		copies[place] = &placeCopy
+		subnet.places.Push(&placeCopy)
+	}
+	for _, tran := range transitions {
+		tranCopy := *tran
+		tranCopy.line, tranCopy.parsed = 0, ""
+		tranCopy.Origins, tranCopy.Targets = tran.Origins.rewired(copies), tran.Targets.rewired(copies)
+		subnet.transitions.Push(&tranCopy)
+	}
+	return subnet
+}
+
+func (subnet Subnet) Places() Places {
+	return subnet.places
+}
+
+func (subnet Subnet) Transitions() Transitions {
+	return subnet.transitions
+}
+
+func (subnet Subnet) IsEmpty() bool {
+	return len(subnet.places) == 0 && len(subnet.transitions) == 0
+}
+
+// AddSubnet adds copy of subnet to net, ids which are already used are replaced by free ones
+// returns the added copy, its places and transitions are in the same order as in subnet
+func (net *Net) AddSubnet(subnet Subnet) Subnet {
+	added := NewSubnet(subnet.places, subnet.transitions)
+	for _, place := range added.places {
+		place.Id = net.unusedId(place.Id)
+		net.AddPlace(place)
+	}
+	for _, tran := range added.transitions {
+		tran.Id = net.unusedId(tran.Id)
+		net.AddTransition(tran)
+	}
+	return added
+}
+
+// returns id if it is not used yet, otherwise free id with the same prefix
+func (net *Net) unusedId(id string) string {
+	if id == "" { // anonymous transitions can not clash
+		return id
+	}
+	for _, place := range net.places {
+		if place.Id == id {
+			return net.FreeId(strings.TrimRight(id, "0123456789"))
+		}
+	}
+	for _, tran := range net.transitions {
+		if tran.Id == id {
+			return net.FreeId(strings.TrimRight(id, "0123456789"))
+		}
+	}
+	return id
+}
+
+// returns copies of arcs leading to copied places, leading to their copies
+func (arcs Arcs) rewired(copies map[*Place]*Place) Arcs {
+	rewired := Arcs{}
+	for _, arc := range arcs {
+		if placeCopy, ok := copies[arc.Place]; ok {
+			rewired = append(rewired, &Arc{arc.Weight, arc.Type, placeCopy})
+		}
+	}
+	return rewired
+}
@@ -0,0 +1,135 @@
+package net
+
+import (
+	"testing"
+)
+
+func TestFreeId(test *testing.T) {
+	network, _ := Parse(`
+		p1 ()
+		p3 ()
+		----
+		p1 -> t1[] -> p3
+	`)
+	if id := network.FreeId("p"); id != "p2" {
+		test.Errorf("expected free id p2, got %s", id)
+	}
+	if id := network.FreeId("t"); id != "t2" {
+		test.Errorf("expected free id t2, got %s", id)
+	}
+}
+
+func TestSetArc(test *testing.T) {
+	network, _ := Parse(`
+		p (1)
+		q ()
+		----
+		[] -> q
+	`)
+	p, q := network.Places()[0], network.Places()[1]
+	t := network.Transitions()[0]
+
+	network.SetArc(p, t, false, 2, NormalArc)
+	if t.String() != "2*p -> [] -> q" {
+		test.Errorf("self loop should be replaced by origin, got %s", t)
+	}
+	network.SetArc(q, t, false, 3, InhibitorArc)
+	if arc := network.Arc(q, t, false); arc == nil || arc.Type != InhibitorArc || arc.Weight != 1 {
+		test.Errorf("inhibitor arc with weight 1 should be added, got %s", t)
+	}
+	network.SetArc(p, t, true, 1, InhibitorArc)
+	if arc := network.Arc(p, t, true); arc == nil || arc.Type != NormalArc {
+		test.Errorf("output arc should not be inhibitor, got %s", t)
+	}
+
+	network.SetArc(p, t, false, 0, NormalArc)
+	network.SetArc(q, t, false, 0, NormalArc)
+	if t.String() != "[] -> q, p" || len(t.Origins) != 1 || !t.Origins[0].IsDumb() {
+		test.Errorf("self loop should be restored, got %s", t)
+	}
+}
+
+func TestRemove(test *testing.T) {
+	network, _ := Parse(`
+		p (1)
+		q ()
+		----
+		p -> t[] -> q
+		q -> u[] -> p
+	`)
+	p := network.Places()[0]
+	t, u := network.Transitions()[0], network.Transitions()[1]
+
+	network.RemovePlace(p)
+	if len(network.Places()) != 1 {
+		test.Errorf("place should be removed")
+	}
+	if t.String() != "t[] -> q" || u.String() != "q -> u[]" {
+		test.Errorf("arcs of place should be removed, got %s, %s", t, u)
+	}
+	if len(t.Origins) != 1 || !t.Origins[0].IsDumb() {
+		test.Errorf("transition without origins should get self loop")
+	}
+
+	network.RemoveTransition(t)
+	if len(network.Transitions()) != 1 || network.Transitions()[0] != u {
+		test.Errorf("transition should be removed")
+	}
+}
+
+func TestSnapshot(test *testing.T) {
+	network, _ := Parse(`
+		p (1) "queue"
+		q ()
+		----
+		p -> t[] -> q
+	`)
+	p, q, t := network.Places()[0], network.Places()[1], network.Transitions()[0]
+	str := network.String()
+	snapshot := network.Snapshot()
+
+	p.Tokens, p.Description = 5, "changed"
+	network.SetArc(q, t, true, 3, NormalArc)
+	network.RemovePlace(p)
+	network.AddTransition(&Transition{Id: "u"})
+
+	network.Restore(snapshot)
+	if network.String() != str {
+		test.Errorf("net should be restored to\n%s\ngot\n%s", str, network)
+	}
+	if network.Places()[0] != p || network.Transitions()[0] != t || network.Arc(p, t, false) == nil {
+		test.Errorf("restored net should consist of the same places and transitions")
+	}
+}
+
+func TestAddSubnet(test *testing.T) {
+	network, _ := Parse(`
+		p1 (1)
+		q ()
+		r ()
+		----
+		p1 -> t1[] -> q, r
+		q -> u[] -> r
+	`)
+	places, transitions := network.Places(), network.Transitions()
+	subnet := NewSubnet(places[:2], transitions[:1])
+
+	added := network.AddSubnet(subnet)
+	if len(network.Places()) != 5 || len(network.Transitions()) != 3 {
+		test.Fatalf("subnet should be added, got %s", network.String())
+	}
+	if str := added.Transitions()[0].String(); str != "p2 -> t2[] -> q1" {
+		test.Errorf("ids should be renamed and only inner arcs kept, got %s", str)
+	}
+	if added.Places()[0].Tokens != 1 {
+		test.Errorf("tokens should be copied, got %s", added.Places()[0])
+	}
+	if str := transitions[0].String(); str != "p1 -> t1[] -> q, r" {
+		test.Errorf("original transition should not change, got %s", str)
+	}
+
+	added = network.AddSubnet(subnet)
+	if str := added.Transitions()[0].String(); str != "p3 -> t3[] -> q2" {
+		test.Errorf("subnet should be added again with another ids, got %s", str)
+	}
+}
@@ -102,11 +102,15 @@ type Place struct {
 	Tokens      int
 	Description string
 	Id          string
+	Color       string // #rrggbb of outline, tokens and description, empty for default
+	Fill        string // #rrggbb of inside, empty for default
 	initTokens  int
+	line        int    // number of line of source it was parsed from, 0 for new places
+	parsed      string // string of place when it was parsed
 }
 
 func (p Place) String() string {
-	return fmt.Sprintf("%s(%d)%s", p.Id, p.Tokens, q(p.Description))
+	return fmt.Sprintf("%s(%d)%s%s", p.Id, p.Tokens, q(p.Description), paint(p.Color, p.Fill))
 }
 
 func (p *Place) Equals(pp *Place) bool {
@@ -249,6 +253,10 @@ type Transition struct {
 	Priority    int
 	TimeFunc    *TimeFunc
 	Description string
+	Color       string // #rrggbb of outline and description, empty for default
+	Fill        string // #rrggbb of inside, empty for default
+	line        int    // number of line of source it was parsed from, 0 for new transitions
+	parsed      string // string of transition when it was parsed
 }
 
 func (t Transition) String() string {
@@ -264,7 +272,7 @@ func (t Transition) String() string {
 	if !t.Targets.IsEmpty() {
 		targets = fmt.Sprintf(" -> %s", t.Targets)
 	}
-	return fmt.Sprintf("%s%s[%s%s]%s%s", origins, t.Id, t.TimeFunc, prio, q(t.Description), targets)
+	return fmt.Sprintf("%s%s[%s%s]%s%s%s", origins, t.Id, t.TimeFunc, prio, q(t.Description), paint(t.Color, t.Fill), targets)
 }
 
 func (t *Transition) Equals(tt *Transition) bool {
@@ -284,6 +292,17 @@ func (t *Transition) Equals(tt *Transition) bool {
 	return true
 }
 
+// EnsureOrigins changes `[] -> n` to `S -> [] -> n,S`
+// where S is hidden place creating self loop
+// so transition without origins is enabled only once at a time
+func (t *Transition) EnsureOrigins() {
+	if len(t.Origins) == 0 {
+		selfLoopPlace := &Place{Tokens: 1, Id: "."}
+		t.Origins.Push(1, selfLoopPlace)
+		t.Targets.Push(1, selfLoopPlace)
+	}
+}
+
 /**
  * How many times can by transition fired with current marking on origins arcs
  */
@@ -348,6 +367,27 @@ func (trans *Transitions) Remove(i int) {
 	*trans = append((*trans)[:i], (*trans)[i+1:]...)
 }
 
+// Names returns unique identifier of each transition
+// anonymous transitions are named by their order eg. t1, t2...
+func (trans Transitions) Names() map[*Transition]string {
+	names := make(map[*Transition]string, len(trans))
+	used := map[string]bool{}
+	for _, tran := range trans {
+		used[tran.Id] = true
+	}
+	for i, tran := range trans {
+		name := tran.Id
+		for n := i + 1; name == ""; n++ {
+			if candidate := "t" + strconv.Itoa(n); !used[candidate] {
+				name = candidate
+				used[name] = true
+			}
+		}
+		names[tran] = name
+	}
+	return names
+}
+
 /* following 3 methods are implemented to satisfy sort.Interface */
 func (trans Transitions) Len() int {
 	return len(trans)
@@ -369,6 +409,17 @@ func (trans Transitions) Less(i, j int) bool {
 	return trans[i].Priority > trans[j].Priority
 }
 
+// returns colour annotation of place or transition
+func paint(color, fill string) string {
+	if color == "" {
+		return ""
+	}
+	if fill == "" {
+		return " " + color
+	}
+	return " " + color + " " + fill
+}
+
 func q(str string) string {
 	if str != "" {
 		return "\"" + str + "\""
@@ -22,6 +22,8 @@ func init() {
 		ID    = `[a-zA-Z][a-zA-Z0-9_]*`
 		NUM   = `(0|([1-9][0-9]*))`
 		STR   = `"[^"]*"`
+		COLOR = `#[0-9a-fA-F]{6}`
+		PAINT = `(?P<color>` + COLOR + `)(` + SP + `(?P<fill>` + COLOR + `))?`
 		CMNT  = `((//)|(--)).*`
 		ARC   = SP + `(!)?(` + NUM + SP + `\*` + SP + `)?` + ID + SP
 		ARCS  = ARC + `(,` + ARC + `)*`
@@ -38,7 +40,7 @@ func init() {
 
 	/** prepare regexps strings **/
 
-	// ID ( NUM? ) STR?
+	// ID ( NUM? ) STR? PAINT?
 	placeREstr := strings.Join([]string{
 		`^`,
 		`(?P<id>` + ID + `)`,
@@ -46,11 +48,12 @@ func init() {
 		`(?P<num>` + NUM + `)?`,
 		`\)`,
 		`(?P<desc>` + STR + `)?`,
-		`(` + CMNT + `)?`,
+		`(` + PAINT + `)?`,
+		`(?P<comment>` + CMNT + `)?`,
 		`$`,
 	}, SP)
 
-	// IDS -> [ ATTR? ] STR? -> IDS
+	// IDS -> [ ATTR? ] STR? PAINT? -> IDS
 	transitionREstr := strings.Join([]string{
 		`^`,
 		`((?P<in>` + ARCS + `)->)?`,
@@ -59,8 +62,9 @@ func init() {
 		`(?P<attr>` + ATTR + `)?`,
 		`\]`, // ]
 		`(?P<desc>` + STR + `)?`,
+		`(` + PAINT + `)?`,
 		`(->(?P<out>` + ARCS + `))?`,
-		`(` + CMNT + `)?`,
+		`(?P<comment>` + CMNT + `)?`,
 		`$`,
 	}, SP)
 
@@ -91,6 +95,8 @@ func Parse(input string) (net Net, err error) {
 			id := getSubmatchString(placeRE, line, "id")
 			num, _ := strconv.Atoi(getSubmatchString(placeRE, line, "num"))
 			desc := getSubmatchString(placeRE, line, "desc")
+			color := getSubmatchString(placeRE, line, "color")
+			fill := getSubmatchString(placeRE, line, "fill")
 
 			if _, exists := namedPlaces[id]; exists {
 				err = errors.New("place with id `" + id + "` is already defined")
@@ -100,7 +106,11 @@ func Parse(input string) (net Net, err error) {
 				Tokens:      num,
 				Description: unPack(desc), // strip first and last char
 				Id:          id,
+				Color:       color,
+				Fill:        fill,
+				line:        i + 1,
 			}
+			place.parsed = place.String()
 			namedPlaces[id] = place
 			net.places.Push(place)
 
@@ -123,6 +133,8 @@ func Parse(input string) (net Net, err error) {
 			listout := getSubmatchString(transitionRE, line, "out")
 			attr := getSubmatchString(transitionRE, line, "attr")
 			desc := getSubmatchString(transitionRE, line, "desc")
+			color := getSubmatchString(transitionRE, line, "color")
+			fill := getSubmatchString(transitionRE, line, "fill")
 
 			getArcsByList := func(list string) Arcs {
 				arcs := Arcs{}
@@ -131,6 +143,7 @@ func Parse(input string) (net Net, err error) {
 					return arcs
 				}
 				for _, pair := range strings.Split(list, ",") {
+					pair = strings.TrimSpace(pair)
 					inhibitory := false
 					if pair[0] == '!' {
 						inhibitory = true
@@ -176,14 +189,6 @@ func Parse(input string) (net Net, err error) {
 				}
 			}
 
-			// changes `[] -> n` to `S -> [] -> n,S`
-			// where S is hidden place creating self loop
-			if len(origins) == 0 {
-				selfLoopPlace := &Place{Tokens: 1, Id: "."}
-				origins.Push(1, selfLoopPlace)
-				targets.Push(1, selfLoopPlace)
-			}
-
 			priority := 0
 			var timeFunc *TimeFunc
 
@@ -212,14 +217,20 @@ func Parse(input string) (net Net, err error) {
 				}
 			}
 
-			net.transitions.Push(&Transition{
+			transition := &Transition{
 				Id:          id,
 				Origins:     origins,
 				Targets:     targets,
 				Priority:    priority,
 				TimeFunc:    timeFunc,
 				Description: unPack(desc),
-			})
+				Color:       color,
+				Fill:        fill,
+				line:        i + 1,
+			}
+			transition.EnsureOrigins()
+			transition.parsed = transition.String()
+			net.transitions.Push(transition)
 
 		} else {
 			if !isEmptyLine(line) && !isPlaceDefinition(line) {
@@ -0,0 +1,114 @@
+package net
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Rewrite returns source the net was parsed from updated to the current state of net
+// unchanged lines (including comments and empty lines) are kept as they are,
+// lines of changed places and transitions are replaced, but keep their indentation and comments,
+// lines of removed ones are left out and new ones are added after the last place or transition
+// lines of places and transitions are updated, so the result can be rewritten again later
+func (net *Net) Rewrite(source string) string {
+	lines := strings.Split(source, "\n")
+
+	places := map[int]*Place{}
+	for _, place := range net.places {
+		if place.line > 0 && place.line <= len(lines) {
+			places[place.line-1] = place
+		} else {
+			place.line = 0 // not from this source, so it is new
+		}
+	}
+	transitions := map[int]*Transition{}
+	for _, tran := range net.transitions {
+		if tran.line > 0 && tran.line <= len(lines) {
+			transitions[tran.line-1] = tran
+		} else {
+			tran.line = 0
+		}
+	}
+
+	// new places follow the last place, or precede the first transition
+	// new transitions follow the last transition, or end the source
+	lastPlace, lastTransition := -1, len(lines)-1
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if isPlaceDefinition(line) {
+			lastPlace = i
+		} else if isTransitionDefinition(line) {
+			if lastPlace == -1 {
+				lastPlace = i - 1
+			}
+			lastTransition = i
+		}
+	}
+	if lastPlace == -1 {
+		lastPlace = lastTransition
+	}
+	if strings.TrimSpace(lines[len(lines)-1]) == "" && lastTransition == len(lines)-1 {
+		lastTransition-- // keep trailing new line last
+		if lastPlace == len(lines)-1 {
+			lastPlace--
+		}
+	}
+
+	rewritten := []string{}
+	add := func(str string) int {
+		rewritten = append(rewritten, str)
+		return len(rewritten)
+	}
+	// adds new places and transitions which belong after line, indented as the line
+	addNew := func(after int) {
+		indent := ""
+		if after >= 0 {
+			indent = indentation(lines[after])
+		}
+		for _, place := range net.places {
+			if place.line == 0 && after == lastPlace {
+				place.parsed = place.String()
+				place.line = add(indent + place.parsed)
+			}
+		}
+		for _, tran := range net.transitions {
+			if tran.line == 0 && after == lastTransition {
+				tran.parsed = tran.String()
+				tran.line = add(indent + tran.parsed)
+			}
+		}
+	}
+	addNew(-1)
+	for i, line := range lines {
+		if place, ok := places[i]; ok {
+			if str := place.String(); str != place.parsed {
+				line = replaceDefinition(placeRE, line, str)
+				place.parsed = str
+			}
+			place.line = add(line)
+		} else if tran, ok := transitions[i]; ok {
+			if str := tran.String(); str != tran.parsed {
+				line = replaceDefinition(transitionRE, line, str)
+				tran.parsed = str
+			}
+			tran.line = add(line)
+		} else if trimmed := strings.TrimSpace(line); !isPlaceDefinition(trimmed) && !isTransitionDefinition(trimmed) {
+			add(line)
+		}
+		addNew(i)
+	}
+	return strings.Join(rewritten, "\n")
+}
+
+// returns line with definition replaced by str, but with indentation and comment of line kept
+func replaceDefinition(re *regexp.Regexp, line string, str string) string {
+	indent := indentation(line)
+	if comment := getSubmatchString(re, strings.TrimSpace(line), "comment"); comment != "" {
+		str += " " + comment
+	}
+	return indent + str
+}
+
+func indentation(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
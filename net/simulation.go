@@ -3,6 +3,7 @@ package net
 import (
 	"fmt"
 	"sort"
+	"sync"
 	"time"
 )
 
@@ -11,6 +12,7 @@ import (
 type Event struct {
 	time       time.Duration
 	transition *Transition
+	since      time.Duration // when the event was scheduled
 }
 
 /* Calendar */
@@ -44,20 +46,34 @@ func (c *Calendar) shift() (time.Duration, *Transition) {
 	return (*c)[0].time, (*c)[0].transition
 }
 
-func (c *Calendar) insertByTime(newTime time.Duration, tran *Transition) {
+func (c *Calendar) insertByTime(newTime time.Duration, tran *Transition, now time.Duration) {
 	if c.isEmpty() {
-		c.Insert(Event{newTime, tran}, 0)
+		c.Insert(Event{newTime, tran, now}, 0)
 		return
 	}
 	i, event := 0, Event{}
 	for i, event = range *c {
 		if newTime < event.time {
-			c.Insert(Event{newTime, tran}, i)
+			c.Insert(Event{newTime, tran, now}, i)
 			return
 		}
 	}
 	// not found, new is biggest
-	c.Insert(Event{newTime, tran}, i+1)
+	c.Insert(Event{newTime, tran, now}, i+1)
+}
+
+/* Transition states */
+
+type transitionState struct {
+	enabled  bool
+	progress float64
+}
+
+// states of transitions at the last state change of simulation
+// they can be read while simulation is running
+type transitionStates struct {
+	mutex  sync.Mutex
+	states map[*Transition]transitionState
 }
 
 /* Simulation */
@@ -69,14 +85,17 @@ type Simulation struct {
 	net               Net
 	calendar          Calendar
 	stateChange       func(time.Duration, time.Duration)
+	beforeFiring      func(*Transition, time.Duration, time.Duration)
 	paused            bool
 	stopped           bool
 	sortedTransitions Transitions
+	stats             *Statistics
+	states            *transitionStates
 }
 
 func NewSimulation(startTime, endTime time.Duration, net Net) Simulation {
 	net.saveState()
-	return Simulation{startTime, endTime, 0, net, Calendar{}, nil, false, false, nil}
+	return Simulation{startTime, endTime, 0, net, Calendar{}, nil, nil, false, false, nil, newStatistics(), &transitionStates{}}
 }
 
 /**
@@ -108,7 +127,7 @@ func (sim *Simulation) scheduleEnabledTimed() {
 		if tran.TimeFunc != nil {
 			max := sim.diffEnabilityVsScheduled(tran) // how many times schedule
 			for i := 0; i < max; i++ {
-				sim.calendar.insertByTime(sim.now+(*tran.TimeFunc)(), tran)
+				sim.calendar.insertByTime(sim.now+(*tran.TimeFunc)(), tran, sim.now)
 			}
 		}
 	}
@@ -131,9 +150,14 @@ func (sim *Simulation) cancelUnenabledTimed() {
 }
 
 func (sim *Simulation) fireEvent(scheduledTran *Transition, before, now time.Duration) {
+	if sim.beforeFiring != nil {
+		sim.beforeFiring(scheduledTran, before, now)
+	}
 	scheduledTran.doIn()
 	sim.cancelUnenabledTimed()
 	scheduledTran.doOut()
+	sim.stats.fired(scheduledTran)
+	sim.saveTransitionStates()
 	sim.stateChange(before, now)
 
 	countOfPasses := 0
@@ -151,12 +175,17 @@ stabilize: // whenever transition is completed, start checking again from bigest
 		}
 		if tran.isEnabled() {
 			if sim.paused {
-				sim.calendar.Insert(Event{now, tran}, 0)
+				sim.calendar.Insert(Event{now, tran, now}, 0)
 				return
 			}
+			if sim.beforeFiring != nil {
+				sim.beforeFiring(tran, now, now)
+			}
 			tran.doIn()
 			sim.cancelUnenabledTimed()
 			tran.doOut()
+			sim.stats.fired(tran)
+			sim.saveTransitionStates()
 			sim.stateChange(now, now)
 			goto stabilize
 		}
@@ -173,16 +202,64 @@ func (sim *Simulation) DoEveryStateChange(fun func(time.Duration, time.Duration)
 	}
 }
 
+// DoBeforeFiring sets function called before each transition fires, so marking is not changed yet
+// it gets the transition, time of previous state change and time of firing
+func (sim *Simulation) DoBeforeFiring(fun func(*Transition, time.Duration, time.Duration)) {
+	sim.beforeFiring = fun
+}
+
+// Statistics returns statistics of simulation collected since its start
+func (sim *Simulation) Statistics() *Statistics {
+	return sim.stats
+}
+
+// saves states of transitions, so they can be read by TransitionState without reading calendar
+func (sim *Simulation) saveTransitionStates() {
+	states := map[*Transition]transitionState{}
+	for _, tran := range sim.net.transitions {
+		progress := -1.0
+		for _, event := range sim.calendar { // calendar is sorted by time
+			if event.transition == tran {
+				progress = 1
+				if event.time > event.since {
+					progress = float64(sim.now-event.since) / float64(event.time-event.since)
+				}
+				break
+			}
+		}
+		states[tran] = transitionState{tran.isEnabled(), progress}
+	}
+	sim.states.mutex.Lock()
+	defer sim.states.mutex.Unlock()
+	sim.states.states = states
+}
+
+// TransitionState returns whether transition is enabled
+// and which part of time between scheduling and occurrence of its nearest event has elapsed
+// progress is negative if transition has no event scheduled
+// it returns state at the last state change, so it can be called while simulation is running
+func (sim *Simulation) TransitionState(tran *Transition) (enabled bool, progress float64) {
+	sim.states.mutex.Lock()
+	defer sim.states.mutex.Unlock()
+	state, ok := sim.states.states[tran]
+	if !ok {
+		return false, -1
+	}
+	return state.enabled, state.progress
+}
+
 func (sim *Simulation) Init() {
 	restartSeed()
 	sim.now = sim.startTime
 	sim.calendar = Calendar{}
+	sim.stats.reset()
 	sim.sortedTransitions = make(Transitions, len(sim.net.transitions))
 	copy(sim.sortedTransitions, sim.net.transitions)
 	sort.Sort(sim.sortedTransitions)
 
 	// schedule empty tran
-	sim.calendar.Insert(Event{sim.startTime, &Transition{}}, 0)
+	sim.calendar.Insert(Event{sim.startTime, &Transition{}, sim.startTime}, 0)
+	sim.saveTransitionStates()
 	// sim.Step() // this causes runtime error
 }
 
@@ -196,8 +273,10 @@ func (sim *Simulation) Step() bool {
 	}
 	sim.stopped = false
 	before := sim.now
+	sim.stats.elapse(sim.net, eventTime-before)
 	sim.now = eventTime
 	sim.fireEvent(tranToFireNow, before, sim.now) // current time and time of event
+	sim.saveTransitionStates()                    // events scheduled after the last state change
 	return true
 }
 
@@ -0,0 +1,147 @@
+package net
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTransitionState(test *testing.T) {
+	network, err := Parse(`
+		p(1)
+		q(1)
+		r()
+		----
+		p -> t[4s] -> p
+		q -> u[1s] -> q
+		r -> v[] -> r
+	`)
+	if err != nil {
+		test.Fatal(err)
+	}
+	t, u, v := network.transitions[0], network.transitions[1], network.transitions[2]
+
+	sim := NewSimulation(0, time.Minute, network)
+	sim.DoEveryStateChange(nil)
+	sim.Init()
+	sim.Step() // schedules timed transitions
+
+	if enabled, progress := sim.TransitionState(t); !enabled || progress != 0 {
+		test.Errorf("t should be enabled and just scheduled, got %v %v", enabled, progress)
+	}
+	if enabled, progress := sim.TransitionState(v); enabled || progress >= 0 {
+		test.Errorf("v should be neither enabled nor scheduled, got %v %v", enabled, progress)
+	}
+
+	sim.Step() // u fires at 1s and is scheduled again
+	if now := sim.GetNow(); now != time.Second {
+		test.Fatalf("simulation should be at 1s, is at %s", now)
+	}
+	if _, progress := sim.TransitionState(t); progress != 0.25 {
+		test.Errorf("quarter of time till t fires should elapse, got %v", progress)
+	}
+	if _, progress := sim.TransitionState(u); progress != 0 {
+		test.Errorf("u should be just scheduled again, got %v", progress)
+	}
+}
+
+func TestTransitionStateWhileRunning(test *testing.T) {
+	network, _ := Parse(`
+		p(1)
+		----
+		p -> t[1s] -> p
+	`)
+	t := network.transitions[0]
+	sim := NewSimulation(0, time.Hour, network)
+	sim.DoEveryStateChange(nil)
+	sim.Init()
+
+	done := make(chan bool)
+	go func() {
+		sim.Run()
+		close(done)
+	}()
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+		}
+		if enabled, progress := sim.TransitionState(t); !enabled || progress > 1 {
+			test.Fatalf("t should be enabled all the time and its progress at most 1, got %v %v", enabled, progress)
+		}
+	}
+}
+
+func TestStatistics(test *testing.T) {
+	network, _ := Parse(`
+		p(1)
+		q()
+		----
+		p -> t[3s] -> q
+		q -> u[1s] -> p
+	`)
+	t, u := network.transitions[0], network.transitions[1]
+	p, q := network.places[0], network.places[1]
+
+	sim := NewSimulation(0, 8*time.Second, network)
+	sim.DoEveryStateChange(nil)
+	sim.Init()
+	for sim.Step() {
+	}
+	// token spends 3s in p and 1s in q, twice
+	stats := sim.Statistics()
+	if duration := stats.Duration(); duration != 8*time.Second {
+		test.Fatalf("statistics should cover 8s, got %s", duration)
+	}
+	if firings := stats.Firings(t); firings != 2 {
+		test.Errorf("t should fire twice, got %d", firings)
+	}
+	if throughput := stats.Throughput(u); throughput != 0.25 {
+		test.Errorf("u should fire 0.25 times per second, got %v", throughput)
+	}
+	if mean := stats.MeanTokens(p); mean != 0.75 {
+		test.Errorf("mean of tokens in p should be 0.75, got %v", mean)
+	}
+	if occupancy := stats.Occupancy(q); occupancy != 0.25 {
+		test.Errorf("q should be occupied quarter of time, got %v", occupancy)
+	}
+	if utilization := stats.Utilization(t); utilization != 0.75 {
+		test.Errorf("t should be enabled three quarters of time, got %v", utilization)
+	}
+
+	sim.Init()
+	if firings := stats.Firings(t); firings != 0 || stats.Duration() != 0 {
+		test.Errorf("statistics should be reset by init")
+	}
+}
+
+func TestDoBeforeFiring(test *testing.T) {
+	network, _ := Parse(`
+		p(1)
+		q()
+		----
+		p -> t[2s] -> q
+		q -> u[] -> p
+	`)
+	t, u := network.transitions[0], network.transitions[1]
+	p, q := network.places[0], network.places[1]
+
+	sim := NewSimulation(0, time.Minute, network)
+	sim.DoEveryStateChange(nil)
+	fired := Transitions{}
+	sim.DoBeforeFiring(func(tran *Transition, before, now time.Duration) {
+		if tran == t && (p.Tokens != 1 || q.Tokens != 0 || before != 0 || now != 2*time.Second) {
+			test.Errorf("t should fire at 2s with marking not changed yet, got %d %d at %s-%s", p.Tokens, q.Tokens, before, now)
+		}
+		if tran == u && (q.Tokens != 1 || before != now) {
+			test.Errorf("u should fire immediately with marking not changed yet, got %d at %s-%s", q.Tokens, before, now)
+		}
+		fired.Push(tran)
+	})
+	sim.Init()
+	sim.Step() // schedules t
+	sim.Step() // t fires and u right after it
+	if len(fired) != 3 || fired[1] != t || fired[2] != u {
+		test.Errorf("t and u should fire, got %v", fired)
+	}
+}
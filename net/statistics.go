@@ -0,0 +1,114 @@
+package net
+
+import (
+	"sync"
+	"time"
+)
+
+// Statistics are collected during simulation
+// they can be read while simulation is running
+type Statistics struct {
+	mutex    sync.Mutex
+	duration time.Duration                 // simulated time covered so far
+	tokens   map[*Place]float64            // sum of tokens multiplied by time in seconds
+	occupied map[*Place]time.Duration      // time when place had at least one token
+	firings  map[*Transition]int           // how many times transition fired
+	enabled  map[*Transition]time.Duration // time when transition was enabled
+}
+
+func newStatistics() *Statistics {
+	stats := &Statistics{}
+	stats.reset()
+	return stats
+}
+
+func (stats *Statistics) reset() {
+	stats.mutex.Lock()
+	defer stats.mutex.Unlock()
+	stats.duration = 0
+	stats.tokens = map[*Place]float64{}
+	stats.occupied = map[*Place]time.Duration{}
+	stats.firings = map[*Transition]int{}
+	stats.enabled = map[*Transition]time.Duration{}
+}
+
+// adds time elapsed in current state of net
+func (stats *Statistics) elapse(network Net, elapsed time.Duration) {
+	if elapsed <= 0 {
+		return
+	}
+	stats.mutex.Lock()
+	defer stats.mutex.Unlock()
+	stats.duration += elapsed
+	for _, place := range network.places {
+		stats.tokens[place] += float64(place.Tokens) * elapsed.Seconds()
+		if place.Tokens > 0 {
+			stats.occupied[place] += elapsed
+		}
+	}
+	for _, tran := range network.transitions {
+		if tran.isEnabled() {
+			stats.enabled[tran] += elapsed
+		}
+	}
+}
+
+func (stats *Statistics) fired(tran *Transition) {
+	stats.mutex.Lock()
+	defer stats.mutex.Unlock()
+	stats.firings[tran]++
+}
+
+// Duration returns simulated time covered by statistics
+func (stats *Statistics) Duration() time.Duration {
+	stats.mutex.Lock()
+	defer stats.mutex.Unlock()
+	return stats.duration
+}
+
+// MeanTokens returns time weighted mean of number of tokens in place
+func (stats *Statistics) MeanTokens(place *Place) float64 {
+	stats.mutex.Lock()
+	defer stats.mutex.Unlock()
+	if stats.duration == 0 {
+		return float64(place.Tokens)
+	}
+	return stats.tokens[place] / stats.duration.Seconds()
+}
+
+// Occupancy returns part of time when place had at least one token
+func (stats *Statistics) Occupancy(place *Place) float64 {
+	stats.mutex.Lock()
+	defer stats.mutex.Unlock()
+	if stats.duration == 0 {
+		return 0
+	}
+	return float64(stats.occupied[place]) / float64(stats.duration)
+}
+
+// Firings returns how many times transition fired
+func (stats *Statistics) Firings(tran *Transition) int {
+	stats.mutex.Lock()
+	defer stats.mutex.Unlock()
+	return stats.firings[tran]
+}
+
+// Throughput returns mean number of firings of transition per second
+func (stats *Statistics) Throughput(tran *Transition) float64 {
+	stats.mutex.Lock()
+	defer stats.mutex.Unlock()
+	if stats.duration == 0 {
+		return 0
+	}
+	return float64(stats.firings[tran]) / stats.duration.Seconds()
+}
+
+// Utilization returns part of time when transition was enabled
+func (stats *Statistics) Utilization(tran *Transition) float64 {
+	stats.mutex.Lock()
+	defer stats.mutex.Unlock()
+	if stats.duration == 0 {
+		return 0
+	}
+	return float64(stats.enabled[tran]) / float64(stats.duration)
+}
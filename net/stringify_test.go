@@ -44,4 +44,93 @@ func TestNetTransEmpty(test *testing.T) {
 		test.Error("Targets of tran should be empty")
 	}
 
-}
\ No newline at end of file
+}
+
+func TestTransitionsNames(test *testing.T) {
+	network, _ := Parse(`
+		p ()
+		----
+		[] -> p
+		t1[] -> p
+		[] -> p
+	`)
+	names := network.Transitions().Names()
+	expected := []string{"t2", "t1", "t3"}
+	for i, tran := range network.Transitions() {
+		if names[tran] != expected[i] {
+			test.Errorf("transition %d should be named %s, not %s", i, expected[i], names[tran])
+		}
+	}
+}
+
+func TestNetColors(test *testing.T) {
+	n, err := Parse(`
+		p (1) "queue" #ff8800
+		q () #00ff00 #eeffee
+		----
+		p -> t[2s] "serve" #0000ff -> q
+	`)
+	if err != nil {
+		test.Fatalf("net with colours should be parsable %v", err)
+	}
+	p, q, t := n.places[0], n.places[1], n.transitions[0]
+	if p.Color != "#ff8800" || p.Fill != "" {
+		test.Errorf("place should have colour #ff8800, got %q %q", p.Color, p.Fill)
+	}
+	if q.Color != "#00ff00" || q.Fill != "#eeffee" {
+		test.Errorf("place should have colour and fill, got %q %q", q.Color, q.Fill)
+	}
+	if t.Color != "#0000ff" || t.Description != "serve" {
+		test.Errorf("transition should have colour #0000ff, got %q", t.Color)
+	}
+
+	again, err := Parse(n.String())
+	if err != nil {
+		test.Fatalf("stringified net with colours should be parsable %v\n%s", err, n)
+	}
+	if again.places[1].Fill != "#eeffee" || again.transitions[0].Color != "#0000ff" {
+		test.Errorf("colours should survive stringification\n%s", n)
+	}
+}
+
+func TestRewrite(test *testing.T) {
+	source := `// queue
+	p (1) "queue" // waiting
+	q ()
+	----
+	p -> t[2s] -> q // serve
+	q -> u[] -> p
+`
+	network, _ := Parse(source)
+	if rewritten := network.Rewrite(source); rewritten != source {
+		test.Errorf("unchanged net should be rewritten as it is\n%s", rewritten)
+	}
+
+	p, q := network.Places()[0], network.Places()[1]
+	t, u := network.Transitions()[0], network.Transitions()[1]
+	p.Tokens = 3
+	network.SetArc(q, t, false, 1, InhibitorArc)
+	network.RemoveTransition(u)
+	r := &Place{Id: network.FreeId("p")}
+	network.AddPlace(r)
+	network.AddTransition(&Transition{Id: "v", Origins: Arcs{{1, NormalArc, r}}})
+
+	expected := `// queue
+	p(3)"queue" // waiting
+	q ()
+	p1(0)
+	----
+	p, !q -> t[2s] -> q // serve
+	p1 -> v[]
+`
+	rewritten := network.Rewrite(source)
+	if rewritten != expected {
+		test.Errorf("expected\n%s\ngot\n%s", expected, rewritten)
+	}
+	if again := network.Rewrite(rewritten); again != rewritten {
+		test.Errorf("rewritten net should be rewritten again as it is\n%s", again)
+	}
+	if _, err := Parse(rewritten); err != nil {
+		test.Errorf("rewritten net should be parsable %v", err)
+	}
+}
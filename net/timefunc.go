@@ -24,8 +24,20 @@ func (fn *TimeFunc) String() string {
 	}
 }
 
+// Definition returns name of distribution (const, unif, exp or erlang)
+// and arguments time function was created with
+// note that first argument of erlang is its shape k
+func (fn *TimeFunc) Definition() (string, []time.Duration) {
+	if def, ok := timeFuncDefs[fn]; ok {
+		return def.name, def.args
+	}
+	return "", nil
+}
+
 func (fn *TimeFunc) SetTextRepr(name string, args ...time.Duration) {
 
+	timeFuncDefs[fn] = timeFuncDef{name, args}
+
 	arguments := make([]string, 0)
 
 	for _, arg := range args {
@@ -48,9 +60,15 @@ func (fn *TimeFunc) SetTextRepr(name string, args ...time.Duration) {
 	}()
 }
 
+type timeFuncDef struct {
+	name string
+	args []time.Duration
+}
+
 /******* global vars *******/
 
 var timeFuncTextReprs map[*TimeFunc]string
+var timeFuncDefs map[*TimeFunc]timeFuncDef
 var startSeed int64 = 1
 
 /******* exported functions *******/
@@ -105,6 +123,7 @@ func TrueRandomSeed() {
 
 func init() {
 	timeFuncTextReprs = make(map[*TimeFunc]string)
+	timeFuncDefs = make(map[*TimeFunc]timeFuncDef)
 }
 
 func restartSeed() {
@@ -0,0 +1,335 @@
+// Package netjson implements reading and writing of net and its composition as JSON
+//
+// Document has following schema:
+//
+//	{
+//	  "places": [
+//	    {"id": "p", "description": "queue", "tokens": 2, "position": {"x": 0, "y": 0}}
+//	  ],
+//	  "transitions": [
+//	    {"id": "t", "description": "", "priority": 0,
+//	     "timing": {"distribution": "exp", "mean": "1s"}, "position": {"x": 90, "y": 0}}
+//	  ],
+//	  "arcs": [
+//	    {"from": "p", "to": "t", "type": "normal", "weight": 1, "path": [{"x": 45, "y": 30}]}
+//	  ]
+//	}
+//
+// Ids of places and transitions are unique among both.
+// Description, tokens, priority, timing, position, color, fill, type, weight and path are optional.
+// Colors are strings in format #rrggbb.
+// Timing is omitted for immediate transitions, otherwise its distribution is one of
+//
+//	const  - with "value"
+//	unif   - with "from" and "to"
+//	exp    - with "mean"
+//	erlang - with "k" and "mean"
+//
+// Durations are strings in format of time.ParseDuration, eg. "1m30s".
+// Arc type is "normal" (default) or "inhibitor", which must lead from place to transition.
+// There is at most one arc in each direction between the same place and transition.
+// Path lists intermediate points of arc, excluding its end points.
+package netjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"git.yo2.cz/drahoslav/penego/compose"
+	"git.yo2.cz/drahoslav/penego/draw"
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+type Document struct {
+	Places      []Place      `json:"places"`
+	Transitions []Transition `json:"transitions"`
+	Arcs        []Arc        `json:"arcs"`
+}
+
+type Position struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+type Place struct {
+	Id          string    `json:"id"`
+	Description string    `json:"description,omitempty"`
+	Tokens      int       `json:"tokens,omitempty"`
+	Position    *Position `json:"position,omitempty"`
+	Color       string    `json:"color,omitempty"`
+	Fill        string    `json:"fill,omitempty"`
+}
+
+type Transition struct {
+	Id          string    `json:"id"`
+	Description string    `json:"description,omitempty"`
+	Priority    int       `json:"priority,omitempty"`
+	Timing      *Timing   `json:"timing,omitempty"`
+	Position    *Position `json:"position,omitempty"`
+	Color       string    `json:"color,omitempty"`
+	Fill        string    `json:"fill,omitempty"`
+}
+
+type Timing struct {
+	Distribution string `json:"distribution"`
+	Value        string `json:"value,omitempty"`
+	From         string `json:"from,omitempty"`
+	To           string `json:"to,omitempty"`
+	Mean         string `json:"mean,omitempty"`
+	K            uint   `json:"k,omitempty"`
+}
+
+type Arc struct {
+	From   string     `json:"from"`
+	To     string     `json:"to"`
+	Type   string     `json:"type,omitempty"`
+	Weight int        `json:"weight,omitempty"`
+	Path   []Position `json:"path,omitempty"`
+}
+
+const (
+	normalArc    = "normal"
+	inhibitorArc = "inhibitor"
+)
+
+func timing(timeFunc *net.TimeFunc) *Timing {
+	if timeFunc == nil {
+		return nil
+	}
+	name, args := timeFunc.Definition()
+	timing := &Timing{Distribution: name}
+	switch name {
+	case "const":
+		timing.Value = args[0].String()
+	case "unif":
+		timing.From, timing.To = args[0].String(), args[1].String()
+	case "exp":
+		timing.Mean = args[0].String()
+	case "erlang":
+		timing.K, timing.Mean = uint(args[0]), args[1].String()
+	}
+	return timing
+}
+
+func (timing *Timing) timeFunc() (*net.TimeFunc, error) {
+	if timing == nil {
+		return nil, nil
+	}
+	durations := map[string]time.Duration{}
+	for key, str := range map[string]string{"value": timing.Value, "from": timing.From, "to": timing.To, "mean": timing.Mean} {
+		if str == "" {
+			continue
+		}
+		d, err := time.ParseDuration(str)
+		if err != nil {
+			return nil, err
+		}
+		durations[key] = d
+	}
+	switch timing.Distribution {
+	case "const":
+		return net.GetConstantTimeFunc(durations["value"]), nil
+	case "unif":
+		return net.GetUniformTimeFunc(durations["from"], durations["to"]), nil
+	case "exp":
+		return net.GetExponentialTimeFunc(durations["mean"]), nil
+	case "erlang":
+		if timing.K < 1 {
+			return nil, fmt.Errorf("k of erlang distribution must be positive")
+		}
+		return net.GetErlangTimeFunc(durations["mean"], timing.K), nil
+	default:
+		return nil, fmt.Errorf("unknown distribution `%s`", timing.Distribution)
+	}
+}
+
+// Write writes net and its composition as JSON document
+func Write(writer io.Writer, network net.Net, composition compose.Composition) error {
+	doc := Document{[]Place{}, []Transition{}, []Arc{}}
+
+	position := func(node compose.Composable) *Position {
+		if pos, ok := composition.Position(node); ok {
+			return &Position{pos.X, pos.Y}
+		}
+		return nil
+	}
+	path := func(from, to compose.Composable) []Position {
+		poss := composition.PathPositions(from, to)
+		path := []Position{}
+		for _, pos := range poss[1 : len(poss)-1] {
+			path = append(path, Position{pos.X, pos.Y})
+		}
+		return path
+	}
+
+	for _, place := range network.Places() {
+		doc.Places = append(doc.Places, Place{place.Id, place.Description, place.Tokens, position(place), place.Color, place.Fill})
+	}
+
+	transitions := network.Transitions()
+	names := transitions.Names()
+	for _, tran := range transitions {
+		doc.Transitions = append(doc.Transitions, Transition{
+			names[tran], tran.Description, tran.Priority, timing(tran.TimeFunc), position(tran), tran.Color, tran.Fill,
+		})
+		for _, arc := range tran.Origins {
+			if arc.Place.Hidden() {
+				continue
+			}
+			arcType := normalArc
+			if arc.Type == net.InhibitorArc {
+				arcType = inhibitorArc
+			}
+			doc.Arcs = append(doc.Arcs, Arc{arc.Place.Id, names[tran], arcType, arc.Weight, path(arc.Place, tran)})
+		}
+		for _, arc := range tran.Targets {
+			if arc.Place.Hidden() {
+				continue
+			}
+			doc.Arcs = append(doc.Arcs, Arc{names[tran], arc.Place.Id, normalArc, arc.Weight, path(tran, arc.Place)})
+		}
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// Parse reads net and its composition from JSON document
+// nodes without position are placed by composer
+func Parse(reader io.Reader, composer func(net.Net) compose.Composition) (net.Net, compose.Composition, error) {
+	fail := func(format string, args ...interface{}) (net.Net, compose.Composition, error) {
+		return net.Net{}, compose.New(), fmt.Errorf(format, args...)
+	}
+
+	doc := Document{}
+	if err := json.NewDecoder(reader).Decode(&doc); err != nil {
+		return fail("can not read json: %s", err)
+	}
+
+	places := net.Places{}
+	transitions := net.Transitions{}
+	placeById := map[string]*net.Place{}
+	tranById := map[string]*net.Transition{}
+	positions := map[compose.Composable]Position{}
+
+	isNewId := func(id string) bool {
+		return id != "" && placeById[id] == nil && tranById[id] == nil
+	}
+	isColor := func(colors ...string) bool {
+		for _, color := range colors {
+			if _, err := draw.ParseColor(color); color != "" && (err != nil || len(color) != 7) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, p := range doc.Places {
+		if !isNewId(p.Id) {
+			return fail("place `%s`: missing or duplicate id", p.Id)
+		}
+		if p.Tokens < 0 {
+			return fail("place `%s`: negative number of tokens", p.Id)
+		}
+		if !isColor(p.Color, p.Fill) {
+			return fail("place `%s`: color is not in format #rrggbb", p.Id)
+		}
+		place := &net.Place{Id: p.Id, Description: p.Description, Tokens: p.Tokens, Color: p.Color, Fill: p.Fill}
+		places.Push(place)
+		placeById[p.Id] = place
+		if p.Position != nil {
+			positions[place] = *p.Position
+		}
+	}
+
+	for _, t := range doc.Transitions {
+		if !isNewId(t.Id) {
+			return fail("transition `%s`: missing or duplicate id", t.Id)
+		}
+		if !isColor(t.Color, t.Fill) {
+			return fail("transition `%s`: color is not in format #rrggbb", t.Id)
+		}
+		timeFunc, err := t.Timing.timeFunc()
+		if err != nil {
+			return fail("transition `%s`: %s", t.Id, err)
+		}
+		tran := &net.Transition{
+			Id:          t.Id,
+			Description: t.Description,
+			Priority:    t.Priority,
+			TimeFunc:    timeFunc,
+			Color:       t.Color,
+			Fill:        t.Fill,
+			Origins:     net.Arcs{},
+			Targets:     net.Arcs{},
+		}
+		transitions.Push(tran)
+		tranById[t.Id] = tran
+		if t.Position != nil {
+			positions[tran] = *t.Position
+		}
+	}
+
+	paths := map[[2]compose.Composable][]Position{}
+	for _, a := range doc.Arcs {
+		weight := a.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		if weight < 0 {
+			return fail("arc `%s->%s`: negative weight", a.From, a.To)
+		}
+		switch {
+		case placeById[a.From] != nil && tranById[a.To] != nil:
+			place, tran := placeById[a.From], tranById[a.To]
+			if _, ok := paths[[2]compose.Composable{place, tran}]; ok {
+				return fail("arc `%s->%s`: duplicate arc", a.From, a.To)
+			}
+			switch a.Type {
+			case "", normalArc:
+				tran.Origins.Push(weight, place)
+			case inhibitorArc:
+				tran.Origins.PushInhibitor(place)
+			default:
+				return fail("arc `%s->%s`: unknown type `%s`", a.From, a.To, a.Type)
+			}
+			paths[[2]compose.Composable{place, tran}] = a.Path
+		case tranById[a.From] != nil && placeById[a.To] != nil:
+			tran, place := tranById[a.From], placeById[a.To]
+			if a.Type != "" && a.Type != normalArc {
+				return fail("arc `%s->%s`: only normal arc may lead from transition", a.From, a.To)
+			}
+			if _, ok := paths[[2]compose.Composable{tran, place}]; ok {
+				return fail("arc `%s->%s`: duplicate arc", a.From, a.To)
+			}
+			tran.Targets.Push(weight, place)
+			paths[[2]compose.Composable{tran, place}] = a.Path
+		default:
+			return fail("arc `%s->%s`: must connect existing place and transition", a.From, a.To)
+		}
+	}
+
+	for _, tran := range transitions {
+		tran.EnsureOrigins()
+	}
+	network := net.New(places, transitions)
+
+	composition := compose.New()
+	if len(positions) < len(places)+len(transitions) {
+		composition = composer(network)
+	}
+	for node, pos := range positions {
+		composition.Move(node, pos.X, pos.Y)
+	}
+	for ends, path := range paths {
+		poss := []draw.Pos{}
+		for _, pos := range path {
+			poss = append(poss, draw.Pos{pos.X, pos.Y})
+		}
+		composition.SetPathPositions(ends[0], ends[1], poss)
+	}
+	return network, composition, nil
+}
@@ -0,0 +1,78 @@
+package netjson
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"git.yo2.cz/drahoslav/penego/compose"
+	"git.yo2.cz/drahoslav/penego/draw"
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+func TestWriteParse(test *testing.T) {
+	origNet, err := net.Parse(`
+		p (2) "some place"
+		q ()
+		----
+		p -> [2s] -> q
+		!q, p -> t[p=2]"desc" -> 3*q
+		q -> [unif(1s,2s)] -> p
+		[erlang(3,4s)] -> p
+	`)
+	if err != nil {
+		test.Fatalf("net.Parse failed with %s", err)
+	}
+	origComposition := compose.GetSimple(origNet)
+	trans, places := origNet.Transitions(), origNet.Places()
+	origComposition.SetPathPositions(trans[0], places[1], []draw.Pos{{30, 60}, {60, 60}})
+
+	buf := &bytes.Buffer{}
+	if err := Write(buf, origNet, origComposition); err != nil {
+		test.Fatalf("Write failed with %s", err)
+	}
+	resNet, resComposition, err := Parse(buf, compose.GetSimple)
+	if err != nil {
+		test.Fatalf("Parse of\n%s\nfailed with %s", buf, err)
+	}
+	if eq, err := resNet.Equals(&origNet); !eq {
+		test.Errorf("Parsed net differs, because %s \n%s\nshould be\n%s\n", err, resNet, origNet)
+	}
+	for i, tran := range resNet.Transitions() {
+		if tran.TimeFunc.String() != trans[i].TimeFunc.String() {
+			test.Errorf("time of %d. transition should be %s, not %s", i, trans[i].TimeFunc, tran.TimeFunc)
+		}
+		origPos, _ := origComposition.Position(trans[i])
+		if pos, _ := resComposition.Position(tran); pos != origPos {
+			test.Errorf("position of %d. transition should be %v, not %v", i, origPos, pos)
+		}
+	}
+	poss := resComposition.PathPositions(resNet.Transitions()[0], resNet.Places()[1])
+	if len(poss) != 4 || poss[1] != (draw.Pos{30, 60}) || poss[2] != (draw.Pos{60, 60}) {
+		test.Errorf("path should be kept, got %v", poss)
+	}
+	if resNet.Transitions()[3].Id != "t4" {
+		test.Errorf("anonymous transition should be named t4, not %s", resNet.Transitions()[3].Id)
+	}
+}
+
+func TestParseErrors(test *testing.T) {
+	inputs := []string{
+		`{"places": [{"id": "p"}, {"id": "p"}]}`,
+		`{"places": [{"id": "p", "tokens": -1}]}`,
+		`{"places": [{"id": "p"}], "transitions": [{"id": "p"}]}`,
+		`{"transitions": [{"id": "t", "timing": {"distribution": "normal"}}]}`,
+		`{"transitions": [{"id": "t", "timing": {"distribution": "exp", "mean": "1 second"}}]}`,
+		`{"places": [{"id": "p"}], "transitions": [{"id": "t"}], "arcs": [{"from": "p", "to": "q"}]}`,
+		`{"places": [{"id": "p"}], "transitions": [{"id": "t"}], "arcs": [{"from": "t", "to": "p", "type": "inhibitor"}]}`,
+		`{"places": [{"id": "p"}], "transitions": [{"id": "t"}], "arcs": [{"from": "p", "to": "t"}, {"from": "p", "to": "t", "weight": 2}]}`,
+		`{"places": [{"id": "p"}], "transitions": [{"id": "t"}], "arcs": [{"from": "p", "to": "t"}, {"from": "p", "to": "t", "type": "inhibitor"}]}`,
+		`{"places": [{"id": "p"}], "transitions": [{"id": "t"}], "arcs": [{"from": "t", "to": "p"}, {"from": "t", "to": "p"}]}`,
+		`{"places": [`,
+	}
+	for _, input := range inputs {
+		if _, _, err := Parse(strings.NewReader(input), compose.GetSimple); err == nil {
+			test.Errorf("Parser should fail on `%s`", input)
+		}
+	}
+}
@@ -5,15 +5,18 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"math"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"git.yo2.cz/drahoslav/penego/compose"
+	"git.yo2.cz/drahoslav/penego/draw"
 	"git.yo2.cz/drahoslav/penego/export"
 	"git.yo2.cz/drahoslav/penego/gui"
+	"git.yo2.cz/drahoslav/penego/history"
 	"git.yo2.cz/drahoslav/penego/net"
-	"git.yo2.cz/drahoslav/penego/pnml"
 	"git.yo2.cz/drahoslav/penego/storage"
 	"github.com/pkg/profile"
 	"github.com/skratchdot/open-golang/open"
@@ -35,6 +38,9 @@ p 75;-45
 
 `
 
+// number of changes, which can be undone
+const historyLength = 100
+
 type State int
 
 const (
@@ -91,10 +97,15 @@ func main() {
 		Set("zoom", 0).
 		Set("png.filename", pwd+string(filepath.Separator)+"image.png").
 		Set("pdf.filename", pwd+string(filepath.Separator)+"image.pdf").
-		Set("svg.filename", pwd+string(filepath.Separator)+"image.svg")
+		Set("svg.filename", pwd+string(filepath.Separator)+"image.svg").
+		Set("tex.filename", pwd+string(filepath.Separator)+"image.tex").
+		Set("dot.positions", true)
 	storage.Of("settings").
 		Set("linewidth", 2.0).
-		Set("composer", "complex")
+		Set("grid", compose.GridSize).
+		Set("tokendots", float64(draw.MaxTokenDots)).
+		Set("composer", "complex").
+		Set("theme", "light")
 	storage.Of("gui.offset").
 		Set("x", 0.0).
 		Set("y", 0.0)
@@ -115,10 +126,27 @@ func main() {
 		trueRandom = false
 		noClose    = true
 		autoStart  = false
+		strict     = false
+
+		dotPositions = true
+		layout       = "complex"
+		theme        = "light"
+		heat         = "off"
 
 		verbose = false
+		tui     = false
 		input   = ""
 		output  = ""
+
+		size      = ""
+		landscape = false
+		fit       = false
+		margin    = 20
+		dpi       = 72
+		multipage = false
+
+		frameInterval = time.Duration(0)
+		maxFrames     = uint(200)
 	)
 
 	flag.DurationVar(&startTime, "start", startTime, "start `time` of simulation")
@@ -129,10 +157,57 @@ func main() {
 	flag.BoolVar(&noClose, "noclose", noClose, "preserve window after simulation ends")
 	flag.BoolVar(&autoStart, "autostart", autoStart, "automatic start of simulation")
 	flag.BoolVar(&verbose, "v", verbose, "be more verbose")
-
-	flag.StringVar(&input, "i", input, "import file - *.(pnml|xml)")
-	flag.StringVar(&output, "o", output, "export file - *.(png|svg|pdf)\n\t(this means no gui)")
+	flag.BoolVar(&tui, "tui", tui, "run simulation in terminal instead of window\n\tkeys: home reset, right step, space play/pause, q quit")
+
+	flag.StringVar(&input, "i", input, "import file - *.(pnml|xml|net|def|lola|json)")
+	flag.BoolVar(&strict, "strict", strict, "reject unsupported constructs of imported file instead of dropping them")
+	flag.StringVar(&output, "o", output, "export file - *.(png|svg|pdf|tex|dot|json|net|def|lola)\n\tor animation of simulation - *.(gif|apng) or frames/%05d.png\n\tor page with player of simulation - *.html\n\t(this means no gui)")
+	flag.StringVar(&size, "size", size, "size of exported image or page\n\tWxH (px or pt), a3, a4, a5, letter, or legal")
+	flag.BoolVar(&landscape, "landscape", landscape, "use landscape orientation of exported page")
+	flag.BoolVar(&fit, "fit", fit, "fit exported image or page to net instead of using -size")
+	flag.IntVar(&margin, "margin", margin, "space around net when -fit is used, or overlap of pages with -multipage")
+	flag.IntVar(&dpi, "dpi", dpi, "resolution of png export, 72 means 1px for 1pt")
+	flag.BoolVar(&multipage, "multipage", multipage, "split pdf into pages of -size, if net does not fit on one")
+	flag.DurationVar(&frameInterval, "interval", frameInterval, "simulated `time` between frames of animation\n\t0 means frame after every state change")
+	flag.UintVar(&maxFrames, "frames", maxFrames, "maximal number of frames of animation or steps of player")
+	flag.BoolVar(&dotPositions, "dotpos", dotPositions, "use positions of nodes in dot export")
+	flag.StringVar(&layout, "layout", layout, "composer used for nets without composition\n\tsimple, complex, force, or orthogonal")
+	flag.StringVar(&heat, "heatmap", heat, "colour nodes by statistics of simulation\n\toff, tokens (mean tokens, throughput), or occupancy (occupancy, utilization)")
+	flag.StringVar(&theme, "theme", theme, "colours and font of net\n\tlight, dark, contrast, or theme file")
 	flag.Parse()
+	storage.Of("export").
+		Set("dot.positions", dotPositions).
+		Set("fit", fit).
+		Set("margin", margin).
+		Set("dpi", dpi).
+		Set("multipage", multipage)
+	if size != "" {
+		width, height, err := export.ParseSize(size)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		storage.Of("export").Set("width", width).Set("height", height)
+	}
+	if st := storage.Of("export"); landscape && st.Int("width") < st.Int("height") {
+		width, height := st.Int("width"), st.Int("height")
+		st.Set("width", height).Set("height", width)
+	}
+	switch layout {
+	case "simple", "complex", "force", "orthogonal":
+		storage.Of("settings").Set("composer", layout)
+	default:
+		log.Fatalln("unknown layout", layout)
+	}
+	if loaded, err := draw.LoadTheme(theme); err != nil {
+		log.Fatalln("can not load theme", err)
+	} else {
+		draw.CurrentTheme = loaded
+	}
+	storage.Of("settings").Set("theme", theme)
+	if _, ok := heatMeasures[heat]; !ok {
+		log.Fatalln("unknown heatmap", heat)
+	}
+	storage.Of("settings").Set("heatmap", heat)
 
 	////////////////////////////////
 
@@ -160,25 +235,59 @@ func main() {
 	composition.CenterTo(0, 0)
 
 	if input != "" {
-		file, err := os.Open(input)
+		network, composition, err = importNet(input, strict)
 		if err != nil {
-			log.Fatalln("cant open import file", err)
+			log.Fatalln("cant import file\n", err)
 			return
 		}
-		defer file.Close()
-		network, composition = pnml.Parse(file)
+		pnString = "" // imported net has no source to be rewritten
 	}
 
 	////////////////////////////////
 
+	if output != "" && heat != "off" { // statistics for heatmap
+		composition.SetHeatmap(collectStatistics(network, startTime, endTime), heatMeasures[heat])
+	}
+
+	if output != "" && export.IsAnimation(output) { // headless simulation
+		delay := time.Second / time.Duration(timeSpeed)
+		err := record(output, network, composition, startTime, endTime, frameInterval, int(maxFrames), delay)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+
+	if output != "" && strings.ToLower(filepath.Ext(output)) == ".html" { // headless simulation player
+		storage.Of("export").Set("html.filename", output)
+		trace := export.RecordTrace(network, startTime, endTime, int(maxFrames))
+		if err := export.Html(composition, trace, time.Second/time.Duration(timeSpeed)); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+
 	if output != "" { // headless mode
-		err := export.ByName(output, composition.DrawWith)
+		exported, err := exportNet(output, network, composition)
+		if !exported {
+			err = export.ByName(output, network, composition)
+		}
 		if err != nil {
 			log.Fatalln(err)
 		}
 		return
 	}
 
+	if tui { // terminal mode
+		if heat != "off" {
+			composition.SetHeatmap(collectStatistics(network, startTime, endTime), heatMeasures[heat])
+		}
+		if err := runTui(network, composition, startTime, endTime, timeFlow, timeSpeed); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+
 	// else gui mode
 
 	gui.Run(func(screen *gui.Screen) { // runs this anon func in goroutine
@@ -187,26 +296,95 @@ func main() {
 
 		var sim net.Simulation
 
+		// changes which can be undone
+		hist := history.New(historyLength)
+
+		// adds change of composition made since before to history
+		arranged := func(before compose.Composition) {
+			after := composition.Copy()
+			hist.Add(history.NewCommand(func() {
+				composition.Restore(after)
+				screen.ForceRedraw(false)
+			}, func() {
+				composition.Restore(before)
+				screen.ForceRedraw(false)
+			}))
+		}
+		// changes composition, so it can be undone
+		arrange := func(change func()) {
+			before := composition.Copy()
+			change()
+			arranged(before)
+		}
+
 		storage.Of("settings").OnChange(func(st storage.Storage, key string) {
 			if key == "settings.composer" {
-				composition = Compose(network)
+				arrange(func() {
+					composition = Compose(network)
+				})
 				sim.Pause()
 				state = Initial
 			}
+			if key == "settings.heatmap" {
+				composition.SetHeatmap(sim.Statistics(), heatMeasures[st.String("heatmap")])
+			}
+			if key == "settings.theme" {
+				if loaded, err := draw.LoadTheme(st.String("theme")); err == nil {
+					draw.CurrentTheme = loaded
+				}
+			}
+			if key == "settings.grid" {
+				if grid := st.Float("grid"); grid > 0 {
+					compose.GridSize = grid
+				}
+			}
+			if key == "settings.tokendots" {
+				draw.MaxTokenDots = int(st.Float("tokendots"))
+			}
 			screen.ForceRedraw(false)
 		})
 		storage.Of("export").OnChange(func(st storage.Storage, key string) {
 			screen.ForceRedraw(false)
 		})
 
-		var onStateChange = func(before, now time.Duration) {
+		// transition whose tokens are travelling along arcs and how far they are
+		var flowing *net.Transition
+		var flowProgress float64
+
+		// waits for given time before transition fires, during its last part tokens it moves travel along arcs
+		// marking is not changed yet, so tokens leave their places only after travelling
+		// they travel for a second, or proportionally shorter if time flow is accelerated,
+		// but at least for quarter of that, so transitions firing without delay are seen too
+		waitAndFlow := func(tran *net.Transition, wait time.Duration) {
+			travel := time.Second / time.Duration(timeSpeed)
+			if _, ok := composition.Position(tran); !ok {
+				travel = 0
+			} else if wait < travel {
+				travel = time.Duration(math.Max(float64(wait), float64(travel/4)))
+			}
+			if wait > travel {
+				time.Sleep(wait - travel)
+			}
+			flowing = tran
+			const frame = time.Second / 30
+			for start := time.Now(); time.Since(start) < travel; time.Sleep(frame) {
+				flowProgress = float64(time.Since(start)) / float64(travel)
+				screen.ForceRedraw(false)
+			}
+			flowing = nil
+		}
+
+		var beforeFiring = func(tran *net.Transition, before, now time.Duration) {
 			switch timeFlow {
 			case NoFlow:
 			case NaturalFlow:
-				time.Sleep((now - before) / time.Duration(timeSpeed))
+				waitAndFlow(tran, (now-before)/time.Duration(timeSpeed))
 			case ContinuousFlow:
-				time.Sleep(time.Second / time.Duration(timeSpeed))
+				waitAndFlow(tran, time.Second/time.Duration(timeSpeed))
 			}
+		}
+
+		var onStateChange = func(before, now time.Duration) {
 			if verbose {
 				log.Println(now, network.Places())
 			}
@@ -214,14 +392,25 @@ func main() {
 			screen.ForceRedraw(false) // must not block
 		}
 
-
 		foo := func() {}
 		_ = foo
 
+		reloadedFilename := filename
 		reloader := makeFileWatcher(func(filename string) {
 			sim.Stop()
-			pnString = read(filename)
-			network, composition = Parse(pnString)
+			content := read(filename)
+			if filename == reloadedFilename && content == pnString { // written by penego, so net is up to date
+				state = New
+				return
+			}
+			pnString = content
+			hist.Clear()                      // history refers to places and transitions of previous net
+			if filename == reloadedFilename { // keep positions of nodes dragged before reload
+				network, composition = Parse(pnString, composition)
+			} else {
+				network, composition = Parse(pnString)
+			}
+			reloadedFilename = filename
 			if verbose {
 				log.Println(network)
 			}
@@ -300,10 +489,10 @@ func main() {
 					return
 				}
 				defer file.Close()
-				str := Stringify(network, composition)
-				file.WriteString(str)
+				pnString = Rewrite(pnString, &network, composition)
+				file.WriteString(pnString)
 				if verbose {
-					fmt.Println(str)
+					fmt.Println(pnString)
 				}
 				reloader.watch(filename)
 			})
@@ -311,14 +500,17 @@ func main() {
 
 		doImport := func() {
 			gui.LoadFile(func(filename string) {
-				file, err := os.Open(filename)
+				importedNet, importedComposition, err := importNet(filename, strict)
 				if err != nil {
-					fmt.Fprintln(os.Stderr, "cant import file", err)
+					fmt.Fprintln(os.Stderr, "cant import file\n", err)
 					return
 				}
-				defer file.Close()
 				screen.Reset()
-				network, composition = pnml.Parse(file)
+				network, composition = importedNet, importedComposition
+				hist.Clear()
+				pnString = "" // edits are not written to previous file
+				reloader.watch("")
+				reloadedFilename = ""
 				sim.Stop()
 				state = New
 				log.Println("net imported", filename)
@@ -330,11 +522,14 @@ func main() {
 
 		doExport := func() {
 			gui.ToggleExport(func(filename string) {
-				err := export.ByName(filename, composition.DrawWith)
+				exported, err := exportNet(filename, network, composition)
+				if !exported {
+					err = export.ByName(filename, network, composition)
+				}
 				if err != nil {
 					log.Println(err)
 				} else {
-					log.Printf("image %s exported\n", filename)
+					log.Printf("%s exported\n", filename)
 				}
 			})
 		}
@@ -343,9 +538,188 @@ func main() {
 			gui.ToggleSettings()
 		}
 
+		// editing of net structure
+
+		editing := false
+		var arcStart compose.Composable   // node new arc is dragged from
+		var arcEnd draw.Pos               // current end of dragged arc
+		var selectFrom, selectTo draw.Pos // corners of selection box
+		selecting := false
+
+		toggleEditing := func() {
+			editing = !editing
+			arcStart = nil
+			if editing {
+				reset() // edits are made to initial marking
+			}
+		}
+
+		// restarts simulation of changed net and writes net back to its file
+		writeBack := func() {
+			if reloadedFilename != "" {
+				if err := reloader.write(pnString); err != nil {
+					log.Println("cant write file", err)
+				}
+			}
+			state = New
+			screen.ForceRedraw(false)
+		}
+		// returns function restoring current state of net, its composition and source
+		snapshot := func() func() {
+			netState, compositionState, source := network.Snapshot(), composition.Copy(), pnString
+			return func() {
+				sim.Stop()
+				network.Restore(netState)
+				composition.Restore(compositionState)
+				pnString = source
+				writeBack()
+			}
+		}
+		// applies change of net, so it can be undone
+		edit := func(change func()) {
+			sim.Stop()
+			before := snapshot()
+			change()
+			pnString = Rewrite(pnString, &network, composition)
+			hist.Add(history.NewCommand(snapshot(), before))
+			writeBack()
+		}
+
+		addNode := func(x, y float64, isTransition bool) {
+			edit(func() {
+				var node compose.Composable
+				if isTransition {
+					tran := &net.Transition{Id: network.FreeId("t")}
+					network.AddTransition(tran)
+					node = tran
+				} else {
+					place := &net.Place{Id: network.FreeId("p")}
+					network.AddPlace(place)
+					node = place
+				}
+				composition.Move(node, x, y)
+			})
+		}
+
+		// adds arc, or increases weight of existing one
+		connect := func(from, to compose.Composable, inhibitor bool) {
+			place, tran, output := arcEnds(from, to)
+			if place == nil || tran == nil {
+				return
+			}
+			edit(func() {
+				weight, arcType := 1, net.NormalArc
+				if inhibitor && !output {
+					arcType = net.InhibitorArc
+				} else if arc := network.Arc(place, tran, output); arc != nil && arc.Type == net.NormalArc {
+					weight = arc.Weight + 1
+				}
+				network.SetArc(place, tran, output, weight, arcType)
+			})
+		}
+
+		// removes selected nodes, or node or arc under cursor
+		remove := func() {
+			if !editing {
+				return
+			}
+			nodes := composition.Selected()
+			if len(nodes) == 0 {
+				if node := composition.HitTest(screen.CursorPos(true)); node != nil {
+					nodes = append(nodes, node)
+				}
+			}
+			if len(nodes) == 0 {
+				return
+			}
+			edit(func() {
+				for _, node := range nodes {
+					switch node := node.(type) {
+					case *net.Place:
+						network.RemovePlace(node)
+						composition.Remove(node)
+					case *net.Transition:
+						network.RemoveTransition(node)
+						composition.Remove(node)
+					case compose.ArcSegment:
+						from, to := node.Ends()
+						if place, tran, output := arcEnds(from, to); place != nil && tran != nil {
+							network.SetArc(place, tran, output, 0, net.NormalArc)
+							composition.SetPathPositions(from, to, nil)
+						}
+					}
+				}
+			})
+		}
+
+		var clipboard compose.Clip // copied subnet
+		copySelected := func() {
+			if len(composition.Selected()) > 0 {
+				clipboard = composition.CopySelected()
+			}
+		}
+		// pastes copied subnet around cursor, with ids renamed if they are already used
+		paste := func() {
+			if !editing || clipboard.IsEmpty() {
+				return
+			}
+			x, y := screen.CursorPos(true)
+			edit(func() {
+				composition.Paste(&network, clipboard, x, y)
+			})
+		}
+
+		// shows window with description and marking of node, or weight and type of arc
+		editProperties := func(node compose.Composable) {
+			// quotes would end description in penego notation
+			clean := func(description string) string {
+				return strings.Replace(description, "\"", "'", -1)
+			}
+			switch node := node.(type) {
+			case *net.Place:
+				gui.EditNode("place "+node.Id, node.Description, node.Tokens, func(description string, tokens int) {
+					edit(func() {
+						node.Description, node.Tokens = clean(description), tokens
+					})
+				})
+			case *net.Transition:
+				name := network.Transitions().Names()[node]
+				gui.EditNode("transition "+name, node.Description, -1, func(description string, _ int) {
+					edit(func() {
+						node.Description = clean(description)
+					})
+				})
+			case compose.ArcSegment:
+				place, tran, output := arcEnds(node.Ends())
+				if place == nil || tran == nil {
+					return
+				}
+				arc := network.Arc(place, tran, output)
+				if arc == nil {
+					return
+				}
+				name := network.Transitions().Names()[tran]
+				title := "arc " + place.Id + " -> " + name
+				if output {
+					title = "arc " + name + " -> " + place.Id
+				}
+				gui.EditArc(title, arc.Weight, arc.Type == net.InhibitorArc, !output, func(weight int, inhibitor bool) {
+					edit(func() {
+						arcType := net.NormalArc
+						if inhibitor {
+							arcType = net.InhibitorArc
+						}
+						network.SetArc(place, tran, output, weight, arcType)
+					})
+				})
+			}
+		}
+
 		center := func() {
 			screen.Reset()
-			composition.CenterTo(0, 0)
+			arrange(func() {
+				composition.CenterTo(0, 0)
+			})
 		}
 		isCenter := func() bool {
 			offset := storage.Of("gui.offset")
@@ -354,7 +728,40 @@ func main() {
 			return !(x == ox && y == oy)
 		}
 		rotate := func() {
-			composition.Rotate()
+			arrange(composition.Rotate)
+		}
+		mirror := func(horizontally bool) func() {
+			return func() {
+				if horizontally {
+					arrange(composition.MirrorHorizontally)
+				} else {
+					arrange(composition.MirrorVertically)
+				}
+			}
+		}
+		align := func(alignment compose.Alignment) func() {
+			return func() {
+				arrange(func() {
+					composition.Align(composition.Selected(), alignment)
+				})
+			}
+		}
+		distribute := func(horizontally bool) func() {
+			return func() {
+				arrange(func() {
+					composition.Distribute(composition.Selected(), horizontally)
+				})
+			}
+		}
+		undo := func() {
+			if hist.Undo() {
+				screen.ForceRedraw(false)
+			}
+		}
+		redo := func() {
+			if hist.Redo() {
+				screen.ForceRedraw(false)
+			}
 		}
 
 		// up bar commands
@@ -370,6 +777,79 @@ func main() {
 		screen.RegisterControl(0, "P", gui.AlwaysIcon(gui.SettingsIcon), "settings", settings, gui.True)
 		screen.RegisterControl(0, "C", gui.AlwaysIcon(gui.CenterOnIcon), "center net", center, isCenter)
 		screen.RegisterControl(0, "T", gui.AlwaysIcon(gui.RotateIcon), "rotate net", rotate, gui.True)
+		screen.RegisterControl(0, "M", func() gui.Icon {
+			if editing {
+				return gui.EditOnIcon
+			} else {
+				return gui.EditOffIcon
+			}
+		}, "edit net", toggleEditing, gui.True)
+
+		// arrangement commands
+		screen.OnKey("H", mirror(true))
+		screen.OnKey("V", mirror(false))
+		screen.OnKey("1", align(compose.AlignLeft))
+		screen.OnKey("2", align(compose.AlignCenter))
+		screen.OnKey("3", align(compose.AlignRight))
+		screen.OnKey("4", align(compose.AlignTop))
+		screen.OnKey("5", align(compose.AlignMiddle))
+		screen.OnKey("6", align(compose.AlignBottom))
+		screen.OnKey("7", distribute(true))
+		screen.OnKey("8", distribute(false))
+		// the same commands are in arrange tab of settings window
+		alignments := map[string]compose.Alignment{
+			"left": compose.AlignLeft, "center": compose.AlignCenter, "right": compose.AlignRight,
+			"top": compose.AlignTop, "middle": compose.AlignMiddle, "bottom": compose.AlignBottom,
+		}
+		storage.Of("settings").OnChange(func(st storage.Storage, key string) {
+			switch key {
+			case "settings.align":
+				align(alignments[st.String("align")])()
+			case "settings.distribute":
+				distribute(st.String("distribute") == "horizontally")()
+			case "settings.mirror":
+				mirror(st.String("mirror") == "horizontally")()
+			default:
+				return
+			}
+			screen.ForceRedraw(false)
+		})
+		screen.OnKey("L", func() {
+			arrange(composition.ResetLabels)
+		})
+		screen.OnKey("ctrl+Z", undo)
+		screen.OnKey("ctrl+shift+Z", redo)
+		screen.OnKey("escape", func() {
+			composition.ClearSelection()
+		})
+		screen.OnKey("delete", remove)
+		screen.OnKey("backspace", remove)
+		screen.OnKey("ctrl+C", copySelected)
+		screen.OnKey("ctrl+V", paste)
+
+		// view commands
+		const zoomStep = 1.25
+		zoom := func(factor float64) func() {
+			return func() {
+				screen.Zoom(factor)
+				screen.ForceRedraw(false)
+			}
+		}
+		screen.OnKey("plus", zoom(zoomStep))
+		screen.OnKey("kpadd", zoom(zoomStep))
+		screen.OnKey("minus", zoom(1/zoomStep))
+		screen.OnKey("kpsubtract", zoom(1/zoomStep))
+		screen.OnKey("0", func() {
+			zoom(1 / draw.Zoom())()
+		})
+		screen.OnKey("F", func() {
+			screen.Fit(composition.Bounds())
+			screen.ForceRedraw(false)
+		})
+		screen.OnScroll(true, func(x, y, delta float64) {
+			screen.ZoomAt(math.Pow(zoomStep, delta), x, y)
+			screen.ForceRedraw(false)
+		})
 
 		// down bar commands (simulation related)
 		screen.RegisterControl(1, "home", gui.AlwaysIcon(gui.BeginIcon), "reset", reset, gui.True)
@@ -386,13 +866,55 @@ func main() {
 			return composition.HitTest(x, y) != nil
 		})
 
+		var node compose.Composable // dragged node
+		var beforeDrag compose.Composition
+		dragging := false
 		screen.OnDrag(true, func(x, y, dx, dy, sx, sy float64, done bool) {
-			node := composition.HitTest(sx, sy)
-			if node != nil { // drag node
+			if !dragging {
+				beforeDrag = composition.Copy()
+				node = composition.HitTest(sx, sy)
+				switch segment := node.(type) {
+				case compose.ArcSegment:
+					if editing { // arc is not bent, so it can be double clicked
+						node = nil
+					} else { // bend arc
+						node = composition.AddWaypoint(segment, sx, sy)
+					}
+				case *net.Place, *net.Transition:
+					if editing { // draw arc instead of moving node
+						arcStart = node
+					}
+				case nil:
+					if screen.ShiftHeld() { // select instead of panning view
+						selecting = true
+						selectFrom = draw.Pos{sx, sy}
+					}
+				}
+				dragging = true
+			}
+			if done {
+				dragging = false
+			}
+			if arcStart != nil { // draw arc
+				arcEnd = draw.Pos{x, y}
+				if done {
+					connect(arcStart, composition.HitTest(x, y), screen.ShiftHeld())
+					arcStart = nil
+				}
+			} else if node != nil { // drag node
 				if done {
-					composition.Move(node, x, y)
+					composition.MoveSelection(node, x, y)
+					if dx != 0 || dy != 0 { // not just click
+						arranged(beforeDrag)
+					}
 				} else {
-					composition.GhostMove(node, x, y)
+					composition.GhostMoveSelection(node, x, y)
+				}
+			} else if selecting { // drag selection box
+				selectTo = draw.Pos{x, y}
+				if done {
+					composition.SelectIn(sx, sy, x, y)
+					selecting = false
 				}
 			} else { // pan view
 				screen.Pan(dx, dy)
@@ -400,6 +922,27 @@ func main() {
 			screen.ForceRedraw(false)
 		})
 
+		screen.OnShiftClick(true, func(x, y float64) {
+			if node := composition.HitTest(x, y); node != nil {
+				composition.ToggleSelected(node)
+				screen.ForceRedraw(false)
+			}
+		})
+
+		screen.OnDoubleClick(true, func(x, y float64) {
+			node := composition.HitTest(x, y)
+			if waypoint, ok := node.(compose.Waypoint); ok { // unbend arc
+				arrange(func() {
+					composition.RemoveWaypoint(waypoint)
+				})
+				screen.ForceRedraw(false)
+			} else if editing && node == nil { // add place, or transition with shift
+				addNode(x, y, screen.ShiftHeld())
+			} else if editing {
+				editProperties(node)
+			}
+		})
+
 		// main state machine
 
 		for state != Exit {
@@ -418,7 +961,25 @@ func main() {
 			case Initial:
 				sim.Init()
 				sim.DoEveryStateChange(onStateChange)
-				screen.SetRedrawFunc(gui.RedrawFunc(composition.DrawWith))
+				sim.DoBeforeFiring(beforeFiring)
+				composition.SetHeatmap(sim.Statistics(), heatMeasures[storage.Of("settings").String("heatmap")])
+				screen.SetRedrawFunc(gui.RedrawFunc(func(drawer draw.Drawer) {
+					composition.DrawStateWith(drawer, sim.TransitionState)
+					if flowing != nil {
+						composition.DrawFlowWith(drawer, flowing, flowProgress)
+					}
+					if pos, ok := composition.Position(arcStart); ok { // arc being drawn
+						drawer.SetStyle(draw.HighlightedStyle)
+						if _, isPlace := arcStart.(*net.Place); isPlace {
+							drawer.DrawInArc([]draw.Pos{pos, arcEnd}, 1)
+						} else {
+							drawer.DrawOutArc([]draw.Pos{pos, arcEnd}, 1)
+						}
+					}
+					if selecting {
+						screen.DrawSelectionBox(selectFrom, selectTo)
+					}
+				}))
 				if autoStart {
 					state = Running
 				} else {
@@ -20,15 +20,37 @@ func Stringify(network net.Net, composition compose.Composition) string {
 	return fmt.Sprintf("%s\n\n%s\n%s\n\n%s", netDelim, network, compDelim, composition)
 }
 
-func Parse(str string) (network net.Net, composition compose.Composition) {
+// Rewrite returns source of net and its composition updated to their current state
+// net section is rewritten line by line, so comments and formatting of unchanged lines are kept
+// composition section is replaced (or added) to keep positions of new nodes
+func Rewrite(source string, network *net.Net, composition compose.Composition) string {
+	parts := splitBy(source, []string{netDelim, compDelim})
+	prefix := ""
+	if parts[netDelim] != "" {
+		prefix = parts[""] + netDelim
+	}
+	netStr := network.Rewrite(sectionOfNet(parts))
+	for !strings.HasSuffix(netStr, "\n\n") { // sections are separated by empty line
+		netStr += "\n"
+	}
+	return fmt.Sprintf("%s%s%s\n\n%s", prefix, netStr, compDelim, composition)
+}
 
-	parts := splitBy(str, []string{netDelim, compDelim})
-	netStr := parts[netDelim]
-	if netStr == "" {
-		netStr = parts[""]
+// returns part of source with net, which is either marked or it is the first one
+func sectionOfNet(parts map[string]string) string {
+	if netStr := parts[netDelim]; netStr != "" {
+		return netStr
 	}
+	return parts[""]
+}
 
-	network, err := net.Parse(netStr)
+// parses net and its composition
+// nodes not found in composition keep their position from previous compositions (matched by id)
+// and the rest is placed near their neighbours
+func Parse(str string, previous ...compose.Composition) (network net.Net, composition compose.Composition) {
+
+	parts := splitBy(str, []string{netDelim, compDelim})
+	network, err := net.Parse(sectionOfNet(parts))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%s\n", err)
 		return
@@ -36,10 +58,10 @@ func Parse(str string) (network net.Net, composition compose.Composition) {
 
 	compoStr := parts[compDelim]
 	if compoStr != "" {
-		composition = compose.Parse(compoStr, network)
-	} else {
-		composition = Compose(network)
+		previous = append([]compose.Composition{compose.Parse(compoStr, network)}, previous...)
 	}
+	composition = compose.Incremental(network, Compose, previous...)
+	composition.PlaceLabels()
 
 	return
 }
@@ -47,11 +69,17 @@ func Parse(str string) (network net.Net, composition compose.Composition) {
 // returns composition based on settings
 func Compose(network net.Net) (composition compose.Composition) {
 	composer := storage.Of("settings").String("composer")
-	if composer == "simple" {
+	switch composer {
+	case "simple":
 		composition = compose.GetSimple(network)
-	} else {
+	case "force":
+		composition = compose.GetForce(network)
+	case "orthogonal":
+		composition = compose.GetOrthogonal(network)
+	default:
 		composition = compose.GetIterative(network)
 	}
+	composition.PlaceLabels()
 	return
 }
 
@@ -69,3 +97,17 @@ func splitBy(str string, delims []string) map[string]string {
 	}
 	return sections
 }
+
+// returns place and transition connected by arc leading from one node to another
+// output is true for arc leading from transition to place
+func arcEnds(from, to compose.Composable) (place *net.Place, tran *net.Transition, output bool) {
+	switch from := from.(type) {
+	case *net.Place:
+		tran, _ = to.(*net.Transition)
+		return from, tran, false
+	case *net.Transition:
+		place, _ = to.(*net.Place)
+		return place, from, true
+	}
+	return nil, nil, false
+}
@@ -5,11 +5,14 @@ package pnml
 
 import (
 	"encoding/xml"
+	"fmt"
 	"io"
+	"log"
 	"strconv"
 	"strings"
 
 	"git.yo2.cz/drahoslav/penego/compose"
+	"git.yo2.cz/drahoslav/penego/draw"
 	"git.yo2.cz/drahoslav/penego/net"
 )
 
@@ -36,6 +39,7 @@ type Place struct {
 	Id       string   `xml:"id,attr"`
 	Name     Val      `xml:"name"`
 	Marking  Val      `xml:"initialMarking"`
+	Capacity Val      `xml:"capacity"` // PIPE
 	Position Position `xml:"graphics>position"`
 }
 
@@ -48,6 +52,10 @@ type Transition struct {
 	Id       string   `xml:"id,attr"`
 	Name     Val      `xml:"name"`
 	Priority int      `xml:"priority>value"`
+	Timed    Val      `xml:"timed"`             // PIPE
+	Rate     Val      `xml:"rate"`              // PIPE
+	Time     Val      `xml:"toolspecific>time"` // CPN
+	Guard    Val      `xml:"guard"`             // CPN
 	Position Position `xml:"graphics>position"`
 }
 
@@ -58,15 +66,42 @@ type Arc struct {
 	Type    Val    `xml:"type"`
 	ArcType Val    `xml:"arctype"`
 	Weight  Val    `xml:"inscription"`
+	// intermediate points of arc
+	//   PIPE uses <arcpath> including both end points
+	//   CPN uses <graphics><position> for bend points only
+	ArcPath   []Position `xml:"arcpath"`
+	Positions []Position `xml:"graphics>position"`
+}
+
+func (a Arc) waypoints() []draw.Pos {
+	poss := []draw.Pos{}
+	if len(a.ArcPath) > 2 {
+		for _, p := range a.ArcPath[1 : len(a.ArcPath)-1] {
+			poss = append(poss, draw.Pos{p.X, p.Y})
+		}
+		return poss
+	}
+	for _, p := range a.Positions {
+		poss = append(poss, draw.Pos{p.X, p.Y})
+	}
+	return poss
 }
 
 // NOTE
 //   PIPE uses <value>
 //   CPN uses <text>
 type Val struct {
-	Text      string `xml:"text"`
-	Value     string `xml:"value"`
-	ValueAttr string `xml:"value,attr"`
+	Text      string   `xml:"text"`
+	Value     string   `xml:"value"`
+	ValueAttr string   `xml:"value,attr"`
+	Offset    Position `xml:"graphics>offset"` // of label related to its element
+}
+
+// sets offset of label of node to the one of its name, if it has any
+func setLabelOffset(composition compose.Composition, node compose.Composable, name Val) {
+	if name.String() != "" && (name.Offset.X != 0 || name.Offset.Y != 0) {
+		composition.SetLabelOffset(node, draw.Pos{name.Offset.X, name.Offset.Y})
+	}
 }
 
 func (v Val) String() string {
@@ -79,108 +114,234 @@ func (v Val) String() string {
 	return v.ValueAttr
 }
 
-func (v Val) Int(def int) int {
-	if v.Text != "" {
-		val, err := strconv.Atoi(v.Value)
-		if err == nil {
-			return val
-		}
+// returns integer value, or def if value is empty
+// ok is false if value is present, but it is not a plain number
+func (v Val) Int(def int) (val int, ok bool) {
+	str := strings.TrimSpace(v.String())
+	if str == "" {
+		return def, true
 	}
-	if v.Value != "" {
-		val, err := strconv.Atoi(v.Value)
-		if err == nil {
-			return val
-		}
-		// PIPE has some values prefixed with Default,
-		parts := strings.SplitAfter(v.Value, "Default,")
-		if len(parts) == 2 {
-			val, err = strconv.Atoi(parts[1])
-			if err == nil {
-				return val
-			}
-		}
+	// PIPE has some values prefixed with Default,
+	str = strings.TrimPrefix(str, "Default,")
+	// CPN uses multisets of UNIT colour set eg. 3`()
+	if str == "()" {
+		return 1, true
 	}
-	return def
+	str = strings.TrimSuffix(str, "`()")
+	val, err := strconv.Atoi(strings.TrimSpace(str))
+	if err != nil {
+		return def, false
+	}
+	return val, true
 }
-func (pnml *Pnml) buildNetCompo() (net.Net, compose.Composition) {
+
+func (pnml *Pnml) buildNetCompo() (net.Net, compose.Composition, []Problem) {
 	composition := compose.New()
+	problems := []Problem{}
+
+	invalid := func(id string, format string, args ...interface{}) {
+		problems = append(problems, Problem{id, fmt.Sprintf(format, args...), false})
+	}
+	unsupported := func(id string, format string, args ...interface{}) {
+		problems = append(problems, Problem{id, fmt.Sprintf(format, args...), true})
+	}
+
+	pnmlPlaces := pnml.Net.Places
+	pnmlTransitions := pnml.Net.Transitions
+	pnmlArcs := pnml.Net.Arcs
+	for _, page := range pnml.Net.Pages {
+		pnmlPlaces = append(pnmlPlaces, page.Places...)
+		pnmlTransitions = append(pnmlTransitions, page.Transitions...)
+		pnmlArcs = append(pnmlArcs, page.Arcs...)
+	}
 
 	places := net.Places{}
 	transitions := net.Transitions{}
+	placeById := map[string]*net.Place{}
+	transitionById := map[string]*net.Transition{}
 
-	buildPlaces := func(pnmlPlaces []Place) {
-		for _, p := range pnmlPlaces {
-			place := &net.Place{
-				Tokens:      p.Marking.Int(0),
-				Id:          p.Id,
-				Description: p.Name.String(),
-			}
+	isNewId := func(kind string, id string) bool {
+		if id == "" {
+			invalid(id, "%s without id", kind)
+			return false
+		}
+		if placeById[id] != nil || transitionById[id] != nil {
+			invalid(id, "duplicate id of %s", kind)
+			return false
+		}
+		return true
+	}
 
-			composition.Move(place, p.Position.X, p.Position.Y)
-			places.Push(place)
+	for _, p := range pnmlPlaces {
+		if !isNewId("place", p.Id) {
+			continue
 		}
+		tokens, ok := p.Marking.Int(0)
+		if !ok {
+			unsupported(p.Id, "initial marking `%s` is not a number", p.Marking)
+		}
+		if tokens < 0 {
+			invalid(p.Id, "negative initial marking %d", tokens)
+			tokens = 0
+		}
+		if capacity, _ := p.Capacity.Int(0); capacity > 0 {
+			unsupported(p.Id, "capacity of place is not supported")
+		}
+		place := &net.Place{
+			Tokens:      tokens,
+			Id:          p.Id,
+			Description: p.Name.String(),
+		}
+
+		composition.Move(place, p.Position.X, p.Position.Y)
+		setLabelOffset(composition, place, p.Name)
+		places.Push(place)
+		placeById[p.Id] = place
 	}
-	buildTransitions := func(pnmlTtransitions []Transition, pnmlArcs []Arc) {
-		for _, t := range pnmlTtransitions {
-			origins := net.Arcs{}
-			targets := net.Arcs{}
 
-			for _, a := range pnmlArcs {
-				if a.Source != t.Id && a.Target != t.Id {
-					continue
-				}
+	for _, t := range pnmlTransitions {
+		if !isNewId("transition", t.Id) {
+			continue
+		}
+		if guard := strings.TrimSpace(t.Guard.String()); guard != "" {
+			unsupported(t.Id, "guard `%s` is not supported", guard)
+		}
+		timeFunc, err := t.timeFunc()
+		if err != nil {
+			unsupported(t.Id, "%s", err)
+		}
+		priority := t.Priority
+		if timeFunc != nil {
+			priority = 0 // timed transitions can not have priority
+		}
+		transition := &net.Transition{
+			Id:          t.Id,
+			Origins:     net.Arcs{},
+			Targets:     net.Arcs{},
+			Priority:    priority,
+			Description: t.Name.String(),
+			TimeFunc:    timeFunc,
+		}
 
-				weight := a.Weight.Int(1)
-				arcType := a.Type.String()
-				if arcType == "" {
-					arcType = a.ArcType.String()
-					// CPN has reverted direction of inhibitor edge
-					if arcType == "inhibitor" {
-						a.Source, a.Target = a.Target, a.Source
-					}
-				}
+		composition.Move(transition, t.Position.X, t.Position.Y)
+		setLabelOffset(composition, transition, t.Name)
+		transitions.Push(transition)
+		transitionById[t.Id] = transition
+	}
 
-				if a.Source == t.Id {
-					targets.Push(weight, places.Find(a.Target))
-				}
-				if a.Target == t.Id {
-					if arcType == "inhibitor" {
-						origins.PushInhibitor(places.Find(a.Source))
-					} else {
-						origins.Push(weight, places.Find(a.Source))
-					}
-				}
+	isConnected := func(arcs net.Arcs, place *net.Place) bool {
+		for _, arc := range arcs {
+			if arc.Place == place {
+				return true
 			}
-			transition := &net.Transition{
-				Id:          t.Id,
-				Origins:     origins,
-				Targets:     targets,
-				Priority:    t.Priority,
-				Description: t.Name.String(),
-				TimeFunc:    nil, // TODO
+		}
+		return false
+	}
+
+	for _, a := range pnmlArcs {
+		weight, ok := a.Weight.Int(1)
+		if !ok {
+			unsupported(a.Id, "inscription `%s` is not a number", a.Weight)
+			continue
+		}
+		if weight < 1 {
+			invalid(a.Id, "weight of arc must be positive, not %d", weight)
+			continue
+		}
+		waypoints := a.waypoints()
+		arcType := a.Type.String()
+		if arcType == "" {
+			arcType = a.ArcType.String()
+			// CPN has reverted direction of inhibitor edge
+			if arcType == "inhibitor" {
+				a.Source, a.Target = a.Target, a.Source
+				for i, j := 0, len(waypoints)-1; i < j; i, j = i+1, j-1 {
+					waypoints[i], waypoints[j] = waypoints[j], waypoints[i]
+				}
 			}
+		}
+		if arcType != "" && arcType != "normal" && arcType != "inhibitor" {
+			unsupported(a.Id, "arc type `%s` is not supported", arcType)
+			continue
+		}
+
+		srcPlace, srcTran := placeById[a.Source], transitionById[a.Source]
+		dstPlace, dstTran := placeById[a.Target], transitionById[a.Target]
 
-			composition.Move(transition, t.Position.X, t.Position.Y)
-			transitions.Push(transition)
+		switch {
+		case srcPlace == nil && srcTran == nil:
+			invalid(a.Id, "source `%s` of arc does not exist", a.Source)
+		case dstPlace == nil && dstTran == nil:
+			invalid(a.Id, "target `%s` of arc does not exist", a.Target)
+		case srcPlace != nil && dstPlace != nil:
+			invalid(a.Id, "arc connects two places `%s` and `%s`", a.Source, a.Target)
+		case srcTran != nil && dstTran != nil:
+			invalid(a.Id, "arc connects two transitions `%s` and `%s`", a.Source, a.Target)
+		case srcPlace != nil: // ( ) -> [ ]
+			if isConnected(dstTran.Origins, srcPlace) {
+				invalid(a.Id, "multiple arcs from `%s` to `%s`", a.Source, a.Target)
+				continue
+			}
+			if arcType == "inhibitor" {
+				dstTran.Origins.PushInhibitor(srcPlace)
+			} else {
+				dstTran.Origins.Push(weight, srcPlace)
+			}
+			composition.SetPathPositions(srcPlace, dstTran, waypoints)
+		case dstPlace != nil: // [ ] -> ( )
+			if arcType == "inhibitor" {
+				invalid(a.Id, "inhibitor arc must lead from place to transition")
+				continue
+			}
+			if isConnected(srcTran.Targets, dstPlace) {
+				invalid(a.Id, "multiple arcs from `%s` to `%s`", a.Source, a.Target)
+				continue
+			}
+			srcTran.Targets.Push(weight, dstPlace)
+			composition.SetPathPositions(srcTran, dstPlace, waypoints)
 		}
 	}
 
-	buildPlaces(pnml.Net.Places)
-	buildTransitions(pnml.Net.Transitions, pnml.Net.Arcs)
-
-	for _, page := range pnml.Net.Pages {
-		buildPlaces(page.Places)
-		buildTransitions(page.Transitions, page.Arcs)
+	for _, tran := range transitions {
+		tran.EnsureOrigins()
 	}
 
-	return net.New(places, transitions), composition
+	return net.New(places, transitions), composition, problems
+}
+
+// Parse reads pnml document and builds net and its composition from it
+// Constructs which can not be represented in penego are dropped and logged
+// Returned error is of type Errors if document is readable but describes invalid net
+func Parse(pnmlReader io.Reader) (net.Net, compose.Composition, error) {
+	return parse(pnmlReader, false)
 }
 
-func Parse(pnmlReader io.Reader) (net.Net, compose.Composition) {
+// ParseStrict is the same as Parse,
+// but unsupported constructs are reported as errors instead of being dropped
+func ParseStrict(pnmlReader io.Reader) (net.Net, compose.Composition, error) {
+	return parse(pnmlReader, true)
+}
+
+func parse(pnmlReader io.Reader, strict bool) (net.Net, compose.Composition, error) {
 	pnml := &Pnml{}
 	decoder := xml.NewDecoder(pnmlReader)
-	decoder.Decode(pnml)
-	net, composition := pnml.buildNetCompo()
+	if err := decoder.Decode(pnml); err != nil {
+		return net.Net{}, compose.New(), fmt.Errorf("can not read pnml: %s", err)
+	}
+	network, composition, problems := pnml.buildNetCompo()
+
+	errs := Errors{}
+	for _, problem := range problems {
+		if problem.Unsupported && !strict {
+			log.Printf("pnml import: %s (dropped)", problem)
+		} else {
+			errs = append(errs, problem)
+		}
+	}
+	if len(errs) > 0 {
+		return net.Net{}, compose.New(), errs
+	}
+
 	composition.CenterTo(0, 0)
-	return net, composition
+	return network, composition, nil
 }
@@ -2,6 +2,7 @@ package pnml
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 
 	"git.yo2.cz/drahoslav/penego/net"
@@ -28,7 +29,10 @@ func TestParse(test *testing.T) {
 		  </net>
 		</pnml>
 	`))
-	resNet, _ := Parse(pnml)
+	resNet, _, err := Parse(pnml)
+	if err != nil {
+		test.Fatalf("Parser failed with %s", err)
+	}
 
 	//  TODO make equal
 	p1 := &net.Place{Id: "p1", Tokens: 3}
@@ -48,3 +52,205 @@ func TestParse(test *testing.T) {
 		test.Errorf("Parser failed, because %s \n%s\nshould be\n%s\n", err, resNet, refNet)
 	}
 }
+
+func TestParseTiming(test *testing.T) {
+	pnml := bytes.NewReader([]byte(`
+		<pnml>
+		  <net>
+		    <transition id="pipe">
+		      <timed><value>true</value></timed>
+		      <priority><value>1</value></priority>
+		      <rate><value>1/100</value></rate>
+		    </transition>
+		    <transition id="immediate">
+		      <timed><value>false</value></timed>
+		      <priority><value>2</value></priority>
+		      <rate><value>1</value></rate>
+		    </transition>
+		    <transition id="cpn">
+		      <toolspecific tool="CPN Tools" version="1.0.0">
+		        <time><text>@+discrete(5,10)</text></time>
+		      </toolspecific>
+		    </transition>
+		  </net>
+		</pnml>
+	`))
+	resNet, _, err := Parse(pnml)
+	if err != nil {
+		test.Fatalf("Parser failed with %s", err)
+	}
+
+	expected := map[string]string{
+		"pipe":      "pipe[exp(1m40s)]",
+		"immediate": "immediate[p=2]",
+		"cpn":       "cpn[5s..10s]",
+	}
+	for _, tran := range resNet.Transitions() {
+		if str := tran.String(); str != expected[tran.Id] {
+			test.Errorf("transition %s should be %s, not %s", tran.Id, expected[tran.Id], str)
+		}
+	}
+}
+
+func TestParseCpnTime(test *testing.T) {
+	expected := map[string]string{
+		"@+5":                       "5s",
+		"@+ 2.5":                    "2.5s",
+		"@+round(exponential(0.5))": "exp(2s)",
+		"@+uniform(1.0,3.0)":        "1s..3s",
+		"@+erlang(3,0.1)":           "erlang(3,10s)",
+		"@+foo()":                   "",
+		"":                          "",
+	}
+	for expr, repr := range expected {
+		if str := parseCpnTime(expr).String(); str != repr {
+			test.Errorf("time %q should be parsed as %q, not %q", expr, repr, str)
+		}
+	}
+}
+
+func TestParseArcPath(test *testing.T) {
+	pnml := bytes.NewReader([]byte(`
+		<pnml>
+		  <net>
+		    <place id="p1">
+		      <graphics><position x="0" y="0"/></graphics>
+		    </place>
+		    <transition id="t1">
+		      <graphics><position x="90" y="90"/></graphics>
+		    </transition>
+		    <arc id="a1" source="p1" target="t1">
+		      <arcpath x="0" y="0"/>
+		      <arcpath x="30" y="60"/>
+		      <arcpath x="90" y="90"/>
+		    </arc>
+		    <arc id="a2" source="t1" target="p1">
+		      <graphics>
+		        <position x="60" y="0"/>
+		        <position x="90" y="30"/>
+		      </graphics>
+		    </arc>
+		  </net>
+		</pnml>
+	`))
+	resNet, comp, err := Parse(pnml)
+	if err != nil {
+		test.Fatalf("Parser failed with %s", err)
+	}
+	p1 := resNet.Places()[0]
+	t1 := resNet.Transitions()[0]
+
+	// composition is centered to 0;0 so everything is shifted by -45;-45
+	if poss := comp.PathPositions(p1, t1); len(poss) != 3 || poss[1].X != -15 || poss[1].Y != 15 {
+		test.Errorf("wrong path from place to transition %v", poss)
+	}
+	if poss := comp.PathPositions(t1, p1); len(poss) != 4 || poss[1].X != 15 || poss[2].Y != -15 {
+		test.Errorf("wrong path from transition to place %v", poss)
+	}
+}
+
+func TestParseErrors(test *testing.T) {
+	_, _, err := Parse(bytes.NewReader([]byte(`<pnml><net><place id="p1"></net></pnml>`)))
+	if err == nil {
+		test.Errorf("malformed xml should not be parsed")
+	}
+
+	pnml := bytes.NewReader([]byte(`
+		<pnml>
+		  <net>
+		    <place id="p1" />
+		    <place id="p2" />
+		    <transition id="t1" />
+		    <transition id="t2" />
+		    <transition id="p2" />
+		    <arc id="a1" source="p1" target="t3" />
+		    <arc id="a2" source="p1" target="p2" />
+		    <arc id="a3" source="t1" target="t2" />
+		    <arc id="a4" source="p1" target="t1" />
+		    <arc id="a5" source="p1" target="t1" />
+		  </net>
+		</pnml>
+	`))
+	_, _, err = Parse(pnml)
+	errs, ok := err.(Errors)
+	if !ok {
+		test.Fatalf("Parser should fail with Errors, not %v", err)
+	}
+	expected := []string{"p2", "a1", "a2", "a3", "a5"}
+	if len(errs) != len(expected) {
+		test.Fatalf("Parser should report %d problems, not %d:\n%s", len(expected), len(errs), errs)
+	}
+	for i, problem := range errs {
+		if problem.Id != expected[i] {
+			test.Errorf("problem %d should relate to %s, not %s", i, expected[i], problem)
+		}
+	}
+}
+
+func TestParseStrict(test *testing.T) {
+	doc := []byte(`
+		<pnml>
+		  <net>
+		    <place id="p1">
+		      <initialMarking><text>2` + "`" + `()</text></initialMarking>
+		      <capacity><value>3</value></capacity>
+		    </place>
+		    <transition id="t1" />
+		    <arc id="a1" source="p1" target="t1">
+		      <type value="reset"/>
+		    </arc>
+		  </net>
+		</pnml>
+	`)
+
+	resNet, _, err := Parse(bytes.NewReader(doc))
+	if err != nil {
+		test.Fatalf("Parser failed with %s", err)
+	}
+	if p1 := resNet.Places()[0]; p1.Tokens != 2 {
+		test.Errorf("place should have 2 tokens, not %d", p1.Tokens)
+	}
+	if t1 := resNet.Transitions()[0]; !t1.Origins.IsEmpty() {
+		test.Errorf("unsupported arc should be dropped, not %s", t1)
+	}
+
+	_, _, err = ParseStrict(bytes.NewReader(doc))
+	if errs, ok := err.(Errors); !ok || len(errs) != 2 {
+		test.Errorf("strict parser should report capacity and arc type, not %v", err)
+	}
+}
+
+func TestParseNameOffset(test *testing.T) {
+	pnml := bytes.NewReader([]byte(`
+		<pnml>
+		  <net>
+		    <place id="p1">
+		      <name>
+		        <value>buffer</value>
+		        <graphics><offset x="0" y="-30" /></graphics>
+		      </name>
+		      <graphics><position x="30" y="30" /></graphics>
+		    </place>
+		    <place id="p2">
+		      <name><value>free</value></name>
+		    </place>
+		  </net>
+		</pnml>
+	`))
+	_, composition, err := Parse(pnml)
+	if err != nil {
+		test.Fatalf("Parser failed with %s", err)
+	}
+	for _, line := range strings.Split(composition.String(), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "p1" && (len(fields) != 3 || fields[2] != "0;-30") {
+			test.Errorf("offset of name should be kept as offset of label, got %s", line)
+		}
+		if fields[0] == "p2" && len(fields) != 2 {
+			test.Errorf("name without offset should be placed automatically, got %s", line)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package pnml
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Problem describes single issue found in pnml document
+type Problem struct {
+	Id          string // id of element the problem relates to
+	Description string
+	Unsupported bool // valid pnml, but not representable in penego
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("element `%s`: %s", p.Id, p.Description)
+}
+
+// Errors is list of problems which prevented import of pnml document
+type Errors []Problem
+
+func (errs Errors) Error() string {
+	strs := make([]string, 0, len(errs))
+	for _, problem := range errs {
+		strs = append(strs, problem.String())
+	}
+	return strings.Join(strs, "\n")
+}
@@ -0,0 +1,126 @@
+package pnml
+
+// mapping of PIPE rates and CPN time inscriptions onto net.TimeFunc
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+// TimeUnit is duration of one unit of model time
+// neither PIPE nor CPN tools use units, so seconds are assumed by default
+var TimeUnit = time.Second
+
+var callRE = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9_.]*)\((.*)\)$`)
+
+// returns time function of transition
+// PIPE uses <timed> flag with exponential <rate>
+// CPN uses <cpn:time> inscription eg. `@+5` or `@+exponential(0.1)`
+// nil is returned for immediate transitions
+func (t Transition) timeFunc() (*net.TimeFunc, error) {
+	if t.Timed.String() == "true" {
+		rate, ok := parseNumber(t.Rate.String())
+		if !ok || rate <= 0 {
+			return nil, fmt.Errorf("rate `%s` is not supported", t.Rate)
+		}
+		return net.GetExponentialTimeFunc(units(1 / rate)), nil
+	}
+	if expr := strings.TrimSpace(t.Time.String()); expr != "" {
+		timeFunc := parseCpnTime(expr)
+		if timeFunc == nil {
+			return nil, fmt.Errorf("time inscription `%s` is not supported", expr)
+		}
+		return timeFunc, nil
+	}
+	return nil, nil
+}
+
+// parses CPN time inscription, returns nil if not supported
+func parseCpnTime(expr string) *net.TimeFunc {
+	expr = strings.Replace(expr, " ", "", -1)
+	expr = strings.TrimPrefix(expr, "@+")
+
+	name, args := parseCall(expr)
+	// rounding does not matter for continuous time
+	for name == "round" || name == "floor" || name == "ceil" || name == "trunc" {
+		if len(args) != 1 {
+			return nil
+		}
+		name, args = parseCall(args[0])
+	}
+	if name == "" {
+		if n, ok := parseNumber(args[0]); ok {
+			return net.GetConstantTimeFunc(units(n))
+		}
+		return nil
+	}
+
+	nums := make([]float64, len(args))
+	for i, arg := range args {
+		n, ok := parseNumber(arg)
+		if !ok {
+			return nil
+		}
+		nums[i] = n
+	}
+
+	switch {
+	case (name == "discrete" || name == "uniform") && len(nums) == 2:
+		return net.GetUniformTimeFunc(units(nums[0]), units(nums[1]))
+	case name == "exponential" && len(nums) == 1 && nums[0] > 0:
+		return net.GetExponentialTimeFunc(units(1 / nums[0]))
+	case name == "erlang" && len(nums) == 2 && nums[0] >= 1 && nums[1] > 0:
+		return net.GetErlangTimeFunc(units(1/nums[1]), uint(nums[0]))
+	}
+	return nil
+}
+
+// splits `name(a,b)` to name and arguments
+// expression which is not a call is returned as only argument with empty name
+func parseCall(expr string) (string, []string) {
+	match := callRE.FindStringSubmatch(expr)
+	if match == nil {
+		return "", []string{expr}
+	}
+	args := []string{}
+	depth, start := 0, 0
+	for i, r := range match[2] {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, match[2][start:i])
+				start = i + 1
+			}
+		}
+	}
+	args = append(args, match[2][start:])
+	return match[1], args
+}
+
+// parses decimal number or fraction like `1/100`
+func parseNumber(str string) (float64, bool) {
+	str = strings.TrimSpace(str)
+	if parts := strings.Split(str, "/"); len(parts) == 2 {
+		a, errA := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+		b, errB := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if errA != nil || errB != nil || b == 0 {
+			return 0, false
+		}
+		return a / b, true
+	}
+	n, err := strconv.ParseFloat(str, 64)
+	return n, err == nil
+}
+
+func units(n float64) time.Duration {
+	return time.Duration(n * float64(TimeUnit))
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"image"
+	"time"
+
+	"git.yo2.cz/drahoslav/penego/compose"
+	"git.yo2.cz/drahoslav/penego/export"
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+var heatMeasures = map[string]compose.HeatMeasure{
+	"off":       compose.HeatOff,
+	"tokens":    compose.HeatTokens,
+	"occupancy": compose.HeatOccupancy,
+}
+
+// at most this many events are simulated to collect statistics
+const maxStatisticsSteps = 1000000
+
+// runs simulation of network from start to end and returns its statistics
+// network is restored to its initial marking afterwards
+func collectStatistics(network net.Net, start, end time.Duration) *net.Statistics {
+	sim := net.NewSimulation(start, end, network)
+	sim.DoEveryStateChange(nil)
+	sim.Init()
+	for i := 0; i < maxStatisticsSteps && sim.Step(); i++ {
+	}
+	sim.Stop()
+	return sim.Statistics()
+}
+
+// runs simulation of network and records it as animation
+// frame is taken after every state change, or every interval of simulated time if interval is positive
+// each frame is shown for given delay and has time of simulation in its caption
+func record(filename string, network net.Net, composition compose.Composition,
+	start, end, interval time.Duration, maxFrames int, delay time.Duration,
+) error {
+	anim, err := export.NewAnimation(filename)
+	if err != nil {
+		return err
+	}
+	full := func() bool {
+		return err != nil || anim.Len() >= maxFrames
+	}
+
+	area := export.AreaOf(composition)
+	var last *image.RGBA // state after last change, shown until next one
+	next := start        // time of next frame in interval mode
+
+	sim := net.NewSimulation(start, end, network)
+	sim.DoEveryStateChange(func(before, now time.Duration) {
+		if interval <= 0 {
+			if !full() {
+				err = anim.AddFrame(export.Frame(composition.DrawWith, area), now.String(), delay)
+			}
+			return
+		}
+		for ; last != nil && next < now && !full(); next += interval {
+			err = anim.AddFrame(last, next.String(), delay)
+		}
+		last = export.Frame(composition.DrawWith, area)
+	})
+	sim.Init()
+	for !full() && sim.Step() {
+	}
+	if last != nil && !full() { // final state
+		err = anim.AddFrame(last, next.String(), delay)
+	}
+	sim.Stop()
+	if err != nil {
+		return err
+	}
+	return anim.Close()
+}
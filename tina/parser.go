@@ -0,0 +1,424 @@
+// Package tina implements parser and writer of .net format of TINA toolbox
+// http://projects.laas.fr/tina/manuals/formats.html
+//
+// Time intervals are mapped to constant or uniform timing,
+// priorities of immediate transitions to `pr` relations.
+// Exponential and Erlang timing can not be expressed, such transitions get [0,w[ interval.
+package tina
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+// TimeUnit is duration of one unit of time used in bounds of intervals
+// TINA supports only integer bounds
+var TimeUnit = time.Second
+
+const specials = "[]():*?!,<>-"
+
+// splits line to tokens
+// names in braces are returned including the braces
+func tokenize(line string) ([]string, error) {
+	tokens := []string{}
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '#':
+			return tokens, nil // comment
+		case c == '{':
+			j := i + 1
+			for ; j < len(line) && line[j] != '}'; j++ {
+				if line[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(line) {
+				return nil, fmt.Errorf("unterminated name `%s`", line[i:])
+			}
+			tokens = append(tokens, line[i:j+1])
+			i = j + 1
+		case strings.HasPrefix(line[i:], "->") || strings.HasPrefix(line[i:], "?-") || strings.HasPrefix(line[i:], "!-"):
+			tokens = append(tokens, line[i:i+2])
+			i += 2
+		case strings.IndexByte(specials, c) >= 0:
+			tokens = append(tokens, line[i:i+1])
+			i++
+		default:
+			j := i
+			for ; j < len(line) && !strings.ContainsRune(" \t\r#{"+specials, rune(line[j])); j++ {
+			}
+			tokens = append(tokens, line[i:j])
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+func unescape(name string) string {
+	if strings.HasPrefix(name, "{") {
+		name = name[1 : len(name)-1]
+		name = strings.NewReplacer(`\{`, `{`, `\}`, `}`, `\\`, `\`).Replace(name)
+	}
+	return name
+}
+
+// parses integer with optional K or M suffix
+func parseInt(str string) (int, error) {
+	multiplier := 1
+	switch {
+	case strings.HasSuffix(str, "K"):
+		multiplier = 1000
+	case strings.HasSuffix(str, "M"):
+		multiplier = 1000000
+	}
+	if multiplier > 1 {
+		str = str[:len(str)-1]
+	}
+	n, err := strconv.Atoi(str)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid number `%s`", str)
+	}
+	return n * multiplier, nil
+}
+
+// arc weight `*`, inhibitor `?-`, test `?` and reset/stopwatch `!` `!-` marks
+func isArcMark(token string) bool {
+	switch token {
+	case "*", "?-", "?", "!", "!-":
+		return true
+	}
+	return false
+}
+
+type arcKey struct {
+	place      string
+	transition string
+	out        bool // from transition to place
+}
+
+type parser struct {
+	places      net.Places
+	transitions net.Transitions
+	placeById   map[string]*net.Place
+	tranById    map[string]*net.Transition
+	weights     map[arcKey]int
+	inhibitors  map[arcKey]bool
+	arcOrder    []arcKey
+	intervals   map[*net.Transition][2]string
+	higher      map[*net.Transition][]*net.Transition // transitions with lower priority
+}
+
+func (p *parser) place(id string) *net.Place {
+	if place, ok := p.placeById[id]; ok {
+		return place
+	}
+	place := &net.Place{Id: id}
+	p.placeById[id] = place
+	p.places.Push(place)
+	return place
+}
+
+func (p *parser) transition(id string) *net.Transition {
+	if tran, ok := p.tranById[id]; ok {
+		return tran
+	}
+	tran := &net.Transition{Id: id, Origins: net.Arcs{}, Targets: net.Arcs{}}
+	p.tranById[id] = tran
+	p.transitions.Push(tran)
+	return tran
+}
+
+// parses `node{*w|?-1}` list, calls addArc for each item
+// returns rest of tokens
+func (p *parser) arcs(tokens []string, addArc func(id string, weight int, inhibitor bool) error) ([]string, error) {
+	for len(tokens) > 0 && tokens[0] != "->" {
+		id := unescape(tokens[0])
+		tokens = tokens[1:]
+		weight, inhibitor := 1, false
+		if len(tokens) >= 2 && isArcMark(tokens[0]) {
+			n, err := parseInt(tokens[1])
+			if err != nil {
+				return nil, err
+			}
+			switch tokens[0] {
+			case "*":
+				weight = n
+			case "?-":
+				if n != 1 {
+					return nil, fmt.Errorf("inhibitor arc of `%s` with threshold %d is not supported", id, n)
+				}
+				inhibitor = true
+			default:
+				return nil, fmt.Errorf("arc type `%s` of `%s` is not supported", tokens[0], id)
+			}
+			tokens = tokens[2:]
+		}
+		if weight == 0 {
+			return nil, fmt.Errorf("arc of `%s` has zero weight", id)
+		}
+		if err := addArc(id, weight, inhibitor); err != nil {
+			return nil, err
+		}
+	}
+	return tokens, nil
+}
+
+func (p *parser) addArc(key arcKey, weight int, inhibitor bool) error {
+	if _, exists := p.weights[key]; !exists {
+		p.arcOrder = append(p.arcOrder, key)
+	} else if inhibitor || p.inhibitors[key] {
+		return fmt.Errorf("place `%s` is connected to `%s` by both inhibitor and normal arc", key.place, key.transition)
+	}
+	p.weights[key] += weight
+	p.inhibitors[key] = inhibitor
+	return nil
+}
+
+// parses optional `: label`
+func label(tokens []string) (string, []string) {
+	if len(tokens) >= 2 && tokens[0] == ":" {
+		return unescape(tokens[1]), tokens[2:]
+	}
+	return "", tokens
+}
+
+func (p *parser) parsePlace(tokens []string) error {
+	if len(tokens) == 0 {
+		return fmt.Errorf("missing name of place")
+	}
+	id := unescape(tokens[0])
+	place := p.place(id)
+	description, tokens := label(tokens[1:])
+	if description != "" {
+		place.Description = description
+	}
+	if len(tokens) >= 3 && tokens[0] == "(" && tokens[2] == ")" {
+		n, err := parseInt(tokens[1])
+		if err != nil {
+			return err
+		}
+		place.Tokens = n
+		tokens = tokens[3:]
+	}
+	// transitions putting tokens to place
+	tokens, err := p.arcs(tokens, func(tid string, weight int, inhibitor bool) error {
+		p.transition(tid)
+		if inhibitor {
+			return fmt.Errorf("inhibitor arc from transition `%s` to place `%s`", tid, id)
+		}
+		return p.addArc(arcKey{id, tid, true}, weight, false)
+	})
+	if err != nil || len(tokens) == 0 {
+		return err
+	}
+	// transitions taking tokens from place
+	tokens, err = p.arcs(tokens[1:], func(tid string, weight int, inhibitor bool) error {
+		p.transition(tid)
+		return p.addArc(arcKey{id, tid, false}, weight, inhibitor)
+	})
+	if err == nil && len(tokens) > 0 {
+		err = fmt.Errorf("unexpected `%s`", tokens[0])
+	}
+	return err
+}
+
+func (p *parser) parseTransition(tokens []string) error {
+	if len(tokens) == 0 {
+		return fmt.Errorf("missing name of transition")
+	}
+	id := unescape(tokens[0])
+	tran := p.transition(id)
+	description, tokens := label(tokens[1:])
+	if description != "" {
+		tran.Description = description
+	}
+	if len(tokens) >= 5 && (tokens[0] == "[" || tokens[0] == "]") && tokens[2] == "," {
+		p.intervals[tran] = [2]string{tokens[1], tokens[3]}
+		tokens = tokens[5:]
+	}
+	tokens, err := p.arcs(tokens, func(pid string, weight int, inhibitor bool) error {
+		p.place(pid)
+		return p.addArc(arcKey{pid, id, false}, weight, inhibitor)
+	})
+	if err != nil || len(tokens) == 0 {
+		return err
+	}
+	tokens, err = p.arcs(tokens[1:], func(pid string, weight int, inhibitor bool) error {
+		p.place(pid)
+		if inhibitor {
+			return fmt.Errorf("inhibitor arc from transition `%s` to place `%s`", id, pid)
+		}
+		return p.addArc(arcKey{pid, id, true}, weight, false)
+	})
+	if err == nil && len(tokens) > 0 {
+		err = fmt.Errorf("unexpected `%s`", tokens[0])
+	}
+	return err
+}
+
+func (p *parser) parsePriority(tokens []string) error {
+	left, right := []*net.Transition{}, []*net.Transition{}
+	side := &left
+	greater := true
+	for _, token := range tokens {
+		switch token {
+		case ">", "<":
+			if side == &right {
+				return fmt.Errorf("unexpected `%s`", token)
+			}
+			greater = token == ">"
+			side = &right
+		default:
+			*side = append(*side, p.transition(unescape(token)))
+		}
+	}
+	if !greater {
+		left, right = right, left
+	}
+	for _, tran := range left {
+		p.higher[tran] = append(p.higher[tran], right...)
+	}
+	return nil
+}
+
+func (p *parser) timeFunc(interval [2]string) (*net.TimeFunc, error) {
+	from, err := strconv.ParseFloat(interval[0], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bound `%s`", interval[0])
+	}
+	if interval[1] == "w" {
+		if from != 0 {
+			return nil, fmt.Errorf("unbounded interval starting at %s is not supported", interval[0])
+		}
+		return nil, nil
+	}
+	to, err := strconv.ParseFloat(interval[1], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bound `%s`", interval[1])
+	}
+	switch {
+	case from == 0 && to == 0:
+		return nil, nil
+	case from == to:
+		return net.GetConstantTimeFunc(units(from)), nil
+	default:
+		return net.GetUniformTimeFunc(units(from), units(to)), nil
+	}
+}
+
+// computes numeric priorities of immediate transitions from `pr` relations
+// each transition gets priority one greater than highest of transitions it has priority over
+func (p *parser) priorities() error {
+	const (
+		unvisited = iota
+		open
+		closed
+	)
+	state := map[*net.Transition]int{}
+	var visit func(tran *net.Transition) error
+	visit = func(tran *net.Transition) error {
+		switch state[tran] {
+		case open:
+			return fmt.Errorf("cyclic priority of transition `%s`", tran.Id)
+		case closed:
+			return nil
+		}
+		state[tran] = open
+		for _, lower := range p.higher[tran] {
+			if lower.TimeFunc != nil || tran.TimeFunc != nil {
+				continue // only immediate transitions may have priority
+			}
+			if err := visit(lower); err != nil {
+				return err
+			}
+			if lower.Priority+1 > tran.Priority {
+				tran.Priority = lower.Priority + 1
+			}
+		}
+		state[tran] = closed
+		return nil
+	}
+	for _, tran := range p.transitions {
+		if err := visit(tran); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Parse reads net in TINA .net format
+func Parse(reader io.Reader) (net.Net, error) {
+	content, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return net.Net{}, err
+	}
+	p := &parser{
+		placeById:  map[string]*net.Place{},
+		tranById:   map[string]*net.Transition{},
+		weights:    map[arcKey]int{},
+		inhibitors: map[arcKey]bool{},
+		intervals:  map[*net.Transition][2]string{},
+		higher:     map[*net.Transition][]*net.Transition{},
+	}
+
+	for i, line := range strings.Split(string(content), "\n") {
+		tokens, err := tokenize(line)
+		if err == nil && len(tokens) > 0 {
+			switch tokens[0] {
+			case "pl":
+				err = p.parsePlace(tokens[1:])
+			case "tr":
+				err = p.parseTransition(tokens[1:])
+			case "pr":
+				err = p.parsePriority(tokens[1:])
+			case "net", "lb", "nt":
+				// name of net, labels and notes are not used
+			default:
+				err = fmt.Errorf("unknown description `%s`", tokens[0])
+			}
+		}
+		if err != nil {
+			return net.Net{}, fmt.Errorf("line %d: %s", i+1, err)
+		}
+	}
+
+	for _, key := range p.arcOrder {
+		place, tran := p.placeById[key.place], p.tranById[key.transition]
+		switch {
+		case key.out:
+			tran.Targets.Push(p.weights[key], place)
+		case p.inhibitors[key]:
+			tran.Origins.PushInhibitor(place)
+		default:
+			tran.Origins.Push(p.weights[key], place)
+		}
+	}
+	for _, tran := range p.transitions {
+		if interval, ok := p.intervals[tran]; ok {
+			timeFunc, err := p.timeFunc(interval)
+			if err != nil {
+				return net.Net{}, fmt.Errorf("transition `%s`: %s", tran.Id, err)
+			}
+			tran.TimeFunc = timeFunc
+		}
+		tran.EnsureOrigins()
+	}
+	if err := p.priorities(); err != nil {
+		return net.Net{}, err
+	}
+
+	return net.New(p.places, p.transitions), nil
+}
+
+func units(n float64) time.Duration {
+	return time.Duration(n * float64(TimeUnit))
+}
@@ -0,0 +1,115 @@
+package tina
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+func TestParse(test *testing.T) {
+	resNet, err := Parse(strings.NewReader(`
+		net example # comment
+		pl p1 : {first place} (3)
+		pl p2 t1*2 ->
+		tr t1 : go [2,2] p1 -> p1
+		tr t2 p1 p2?-1 -> p2
+		tr t3 [1,4] p2 ->
+		pr t2 > t4
+		tr t4 p1*1K ->
+	`))
+	if err != nil {
+		test.Fatalf("Parser failed with %s", err)
+	}
+
+	p1 := &net.Place{Id: "p1", Tokens: 3, Description: "first place"}
+	p2 := &net.Place{Id: "p2"}
+	refNet := net.New(
+		net.Places{p1, p2},
+		net.Transitions{
+			{Id: "t1", Description: "go", Origins: net.Arcs{{Weight: 1, Place: p1}}, Targets: net.Arcs{{Weight: 2, Place: p2}, {Weight: 1, Place: p1}}},
+			{Id: "t2", Priority: 1, Origins: net.Arcs{{Weight: 1, Place: p1}, {Weight: 1, Type: net.InhibitorArc, Place: p2}}, Targets: net.Arcs{{Weight: 1, Place: p2}}},
+			{Id: "t3", Origins: net.Arcs{{Weight: 1, Place: p2}}, Targets: net.Arcs{}},
+			{Id: "t4", Origins: net.Arcs{{Weight: 1000, Place: p1}}, Targets: net.Arcs{}},
+		},
+	)
+	if eq, err := resNet.Equals(&refNet); !eq {
+		test.Errorf("Parser failed, because %s \n%s\nshould be\n%s\n", err, resNet, refNet)
+	}
+
+	trans := resNet.Transitions()
+	if trans[0].Description != "go" {
+		test.Errorf("description of t1 should be `go`, not `%s`", trans[0].Description)
+	}
+	if str := trans[0].TimeFunc.String(); str != net.GetConstantTimeFunc(2*time.Second).String() {
+		test.Errorf("t1 should have constant time, not %s", str)
+	}
+	if str := trans[2].TimeFunc.String(); str != net.GetUniformTimeFunc(time.Second, 4*time.Second).String() {
+		test.Errorf("t3 should have uniform time, not %s", str)
+	}
+	if trans[1].Priority != 1 || trans[3].Priority != 0 {
+		test.Errorf("t2 should have priority over t4, got %d and %d", trans[1].Priority, trans[3].Priority)
+	}
+}
+
+func TestParseErrors(test *testing.T) {
+	inputs := []string{
+		"tr t p?-2 ->",      // threshold of inhibitor arc
+		"tr t p?1 ->",       // test arc
+		"tr t [3,w[ p ->",   // unbounded interval
+		"tr t -> p?-1",      // inhibitor from transition
+		"pl {p",             // unterminated name
+		"tr a ->\npr a > a", // cyclic priority
+		"place p",           // unknown description
+		"tr t p p?-1 ->",    // inhibitor and normal arc
+	}
+	for _, input := range inputs {
+		if _, err := Parse(strings.NewReader(input)); err == nil {
+			test.Errorf("Parser should fail on `%s`", input)
+		}
+	}
+}
+
+func TestWriteParse(test *testing.T) {
+	origNet, err := net.Parse(`
+		p (2) "some place"
+		q ()
+		----
+		p -> [2s] -> q
+		!q, p -> [p=1] -> 3*q
+		q -> [unif(1s,2s)] -> p
+		[exp(1s)] -> p
+	`)
+	if err != nil {
+		test.Fatalf("net.Parse failed with %s", err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Write(buf, origNet); err != nil {
+		test.Fatalf("Write failed with %s", err)
+	}
+	resNet, err := Parse(buf)
+	if err != nil {
+		test.Fatalf("Parse of\n%s\nfailed with %s", buf, err)
+	}
+	if eq, err := resNet.Equals(&origNet); !eq {
+		test.Errorf("Written net differs, because %s \n%s\nshould be\n%s\n", err, resNet, origNet)
+	}
+	resTrans, origTrans := resNet.Transitions(), origNet.Transitions()
+	for i, tran := range origTrans[:3] {
+		if resTrans[i].TimeFunc.String() != tran.TimeFunc.String() {
+			test.Errorf("time of %d. transition should be %s, not %s", i, tran.TimeFunc, resTrans[i].TimeFunc)
+		}
+		if resTrans[i].Priority != tran.Priority {
+			test.Errorf("priority of %d. transition should be %d, not %d", i, tran.Priority, resTrans[i].Priority)
+		}
+	}
+	if resTrans[3].TimeFunc != nil {
+		test.Errorf("exponential time should be written as [0,w[ interval")
+	}
+	if resNet.Places()[0].Description != "some place" {
+		test.Errorf("description should be kept, got `%s`", resNet.Places()[0].Description)
+	}
+}
@@ -0,0 +1,131 @@
+package tina
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+var plainNameRE = regexp.MustCompile(`^[a-zA-Z0-9_']+$`)
+
+// returns name usable in .net file, braced if needed
+func escape(name string) string {
+	if plainNameRE.MatchString(name) {
+		return name
+	}
+	name = strings.NewReplacer(`\`, `\\`, `{`, `\{`, `}`, `\}`).Replace(name)
+	return "{" + name + "}"
+}
+
+func formatUnits(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(TimeUnit), 'f', -1, 64)
+}
+
+// returns time interval of transition, empty string for immediate transition
+// exponential and erlang distributions are not supported by TINA
+// so such transitions can fire anytime
+func interval(tran *net.Transition) string {
+	name, args := tran.TimeFunc.Definition()
+	switch {
+	case tran.TimeFunc == nil:
+		return ""
+	case name == "const":
+		return fmt.Sprintf("[%s,%s]", formatUnits(args[0]), formatUnits(args[0]))
+	case name == "unif":
+		return fmt.Sprintf("[%s,%s]", formatUnits(args[0]), formatUnits(args[1]))
+	default:
+		return "[0,w["
+	}
+}
+
+func arcs(arcs net.Arcs) string {
+	strs := []string{}
+	for _, arc := range arcs {
+		if arc.Place.Hidden() {
+			continue
+		}
+		str := escape(arc.Place.Id)
+		switch {
+		case arc.Type == net.InhibitorArc:
+			str += "?-1"
+		case arc.Weight > 1:
+			str += "*" + strconv.Itoa(arc.Weight)
+		}
+		strs = append(strs, str)
+	}
+	return strings.Join(strs, " ")
+}
+
+// Write writes net in TINA .net format
+func Write(writer io.Writer, network net.Net) error {
+	lines := []string{"net penego"}
+
+	for _, place := range network.Places() {
+		line := "pl " + escape(place.Id)
+		if place.Description != "" {
+			line += " : " + escape(place.Description)
+		}
+		if place.Tokens > 0 {
+			line += fmt.Sprintf(" (%d)", place.Tokens)
+		}
+		lines = append(lines, line)
+	}
+
+	transitions := network.Transitions()
+	names := transitions.Names()
+	for _, tran := range transitions {
+		line := "tr " + escape(names[tran])
+		if tran.Description != "" {
+			line += " : " + escape(tran.Description)
+		}
+		if interval := interval(tran); interval != "" {
+			line += " " + interval
+		}
+		if origins := arcs(tran.Origins); origins != "" {
+			line += " " + origins
+		}
+		line += " ->"
+		if targets := arcs(tran.Targets); targets != "" {
+			line += " " + targets
+		}
+		lines = append(lines, line)
+	}
+
+	// immediate transitions of each priority level have priority over lower levels
+	// and all of them have priority over timed transitions
+	levels := map[int][]string{}
+	timed := []string{}
+	for _, tran := range transitions {
+		if tran.TimeFunc == nil {
+			levels[tran.Priority] = append(levels[tran.Priority], escape(names[tran]))
+		} else {
+			timed = append(timed, escape(names[tran]))
+		}
+	}
+	priorities := []int{}
+	for priority := range levels {
+		priorities = append(priorities, priority)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(priorities)))
+	for i := 1; i < len(priorities); i++ {
+		lines = append(lines, fmt.Sprintf("pr %s > %s",
+			strings.Join(levels[priorities[i-1]], " "),
+			strings.Join(levels[priorities[i]], " "),
+		))
+	}
+	if len(priorities) > 0 && len(timed) > 0 {
+		lines = append(lines, fmt.Sprintf("pr %s > %s",
+			strings.Join(levels[priorities[len(priorities)-1]], " "),
+			strings.Join(timed, " "),
+		))
+	}
+
+	_, err := io.WriteString(writer, strings.Join(lines, "\n")+"\n")
+	return err
+}
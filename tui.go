@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"git.yo2.cz/drahoslav/penego/compose"
+	"git.yo2.cz/drahoslav/penego/net"
+	"git.yo2.cz/drahoslav/penego/tui"
+)
+
+// runs simulation of network in terminal
+// keys are the same as of down bar of gui: home resets, right steps and space plays or pauses simulation, Q quits
+func runTui(network net.Net, composition compose.Composition,
+	startTime, endTime time.Duration, timeFlow TimeFlow, timeSpeed uint,
+) error {
+	term, err := tui.Open()
+	if err != nil {
+		return err
+	}
+	defer term.Close()
+
+	var mutex sync.Mutex // guards state and drawing, simulation is only drawn by itself while running
+	state := Paused
+	sim := net.NewSimulation(startTime, endTime, network)
+
+	redraw := func() {
+		cols, rows := term.Size()
+		if rows < 2 {
+			return
+		}
+		left, top, right, bottom := composition.Bounds()
+		drawer := tui.NewDrawer(cols, rows-1, left, top, right, bottom)
+		composition.DrawStateWith(drawer, sim.TransitionState)
+
+		status := map[State]string{Running: "running", Paused: "paused", Idle: "done"}[state]
+		bar := fmt.Sprintf(" [home] reset  [→] step  [space] play/pause  [q] quit   %s %s", sim.GetNow(), status)
+		if len([]rune(bar)) > cols {
+			bar = string([]rune(bar)[:cols])
+		}
+		term.Render(drawer.String() + "\n\x1b[7m" + bar + strings.Repeat(" ", cols-len([]rune(bar))) + "\x1b[0m")
+	}
+
+	sim.DoEveryStateChange(func(before, now time.Duration) {
+		switch timeFlow {
+		case NoFlow:
+		case NaturalFlow:
+			time.Sleep((now - before) / time.Duration(timeSpeed))
+		case ContinuousFlow:
+			time.Sleep(time.Second / time.Duration(timeSpeed))
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		redraw()
+	})
+
+	var finished chan bool // closed when the last run of simulation finishes
+	init := func() {
+		sim.Init()
+		sim.Pause() // so next run continues instead of starting again
+		state = Paused
+	}
+	// pauses running simulation and waits for it
+	pause := func() {
+		mutex.Lock()
+		running, done := state == Running, finished
+		if running {
+			state = Paused
+			sim.Pause()
+		}
+		mutex.Unlock()
+		if running {
+			<-done
+		}
+	}
+
+	init()
+	redraw()
+	for key := range term.Keys() {
+		switch key {
+		case "Q":
+			pause()
+			sim.Stop()
+			return nil
+		case "home": // reset
+			pause()
+			sim.Stop()
+			init()
+		case "right": // step
+			pause()
+			if !sim.Step() {
+				state = Idle
+			}
+		case "space": // play/pause
+			mutex.Lock()
+			current := state
+			if current == Paused {
+				state = Running
+				finished = make(chan bool)
+			}
+			mutex.Unlock()
+			switch current {
+			case Running:
+				pause()
+			case Paused:
+				mutex.Lock()
+				redraw() // shows it is running, before it starts changing
+				mutex.Unlock()
+				go func(done chan bool) {
+					sim.Run()
+					mutex.Lock()
+					if state == Running { // not paused, so it reached its end
+						state = Idle
+					}
+					mutex.Unlock()
+					close(done)
+				}(finished)
+			}
+		}
+		mutex.Lock()
+		if state != Running { // running simulation redraws itself, it must not be read while it changes
+			redraw()
+		}
+		mutex.Unlock()
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+// Package tui renders nets to terminal using braille characters and ANSI colours
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// Canvas is grid of terminal cells, each consisting of 2×4 braille dots
+// texts written to canvas cover dots of their cells
+type Canvas struct {
+	cols, rows int
+	dots       []byte             // braille bits of each cell
+	texts      map[int]rune       // characters of texts by index of cell
+	colors     map[int]color.RGBA // colours of cells, default colour of terminal if missing
+}
+
+func NewCanvas(cols, rows int) *Canvas {
+	return &Canvas{cols, rows, make([]byte, cols*rows), map[int]rune{}, map[int]color.RGBA{}}
+}
+
+// size of canvas in dots
+func (c *Canvas) Size() (width, height int) {
+	return c.cols * 2, c.rows * 4
+}
+
+// bits of braille dots in cell, by x and y of dot within cell
+var brailleBits = [4][2]byte{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// returns index of cell containing dot, -1 if it is outside of canvas
+func (c *Canvas) cell(x, y int) int {
+	if x < 0 || y < 0 || x >= c.cols*2 || y >= c.rows*4 {
+		return -1
+	}
+	return y/4*c.cols + x/2
+}
+
+// Set sets dot at x, y, clr is colour of its cell, nil keeps default colour of terminal
+func (c *Canvas) Set(x, y int, clr *color.RGBA) {
+	i := c.cell(x, y)
+	if i < 0 {
+		return
+	}
+	c.dots[i] |= brailleBits[y%4][x%2]
+	c.setColor(i, clr)
+}
+
+func (c *Canvas) setColor(i int, clr *color.RGBA) {
+	if clr != nil {
+		c.colors[i] = *clr
+	} else {
+		delete(c.colors, i)
+	}
+}
+
+// Line sets dots along line from a to b
+func (c *Canvas) Line(ax, ay, bx, by float64, clr *color.RGBA) {
+	steps := int(math.Max(math.Abs(bx-ax), math.Abs(by-ay)))
+	if steps == 0 {
+		c.Set(round(ax), round(ay), clr)
+		return
+	}
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		c.Set(round(ax+(bx-ax)*t), round(ay+(by-ay)*t), clr)
+	}
+}
+
+// Circle sets dots on circle with center x, y
+func (c *Canvas) Circle(x, y, r float64, clr *color.RGBA) {
+	steps := int(math.Max(8, 2*math.Pi*r))
+	for i := 0; i < steps; i++ {
+		angle := 2 * math.Pi * float64(i) / float64(steps)
+		c.Set(round(x+r*math.Cos(angle)), round(y+r*math.Sin(angle)), clr)
+	}
+}
+
+// Rect sets dots on edges of rectangle, or inside it if filled
+func (c *Canvas) Rect(left, top, right, bottom float64, filled bool, clr *color.RGBA) {
+	if filled {
+		for y := round(top); y <= round(bottom); y++ {
+			c.Line(left, float64(y), right, float64(y), clr)
+		}
+		return
+	}
+	c.Line(left, top, right, top, clr)
+	c.Line(right, top, right, bottom, clr)
+	c.Line(right, bottom, left, bottom, clr)
+	c.Line(left, bottom, left, top, clr)
+}
+
+// Colorize sets colour of cells containing dots of rectangle without setting dots
+func (c *Canvas) Colorize(left, top, right, bottom float64, clr *color.RGBA) {
+	for y := round(top); y <= round(bottom); y++ {
+		for x := round(left); x <= round(right); x++ {
+			if i := c.cell(x, y); i >= 0 {
+				c.setColor(i, clr)
+			}
+		}
+	}
+}
+
+// Text writes text centered at dot x, y
+func (c *Canvas) Text(x, y float64, text string, clr *color.RGBA) {
+	runes := []rune(text)
+	col, row := round(x)/2-len(runes)/2, round(y)/4
+	if row < 0 || row >= c.rows {
+		return
+	}
+	for i, r := range runes {
+		if col+i < 0 || col+i >= c.cols {
+			continue
+		}
+		index := row*c.cols + col + i
+		c.texts[index] = r
+		c.setColor(index, clr)
+	}
+}
+
+// String returns content of canvas as lines with ANSI colour codes
+func (c *Canvas) String() string {
+	buf := &bytes.Buffer{}
+	for row := 0; row < c.rows; row++ {
+		var current *color.RGBA
+		for col := 0; col < c.cols; col++ {
+			i := row*c.cols + col
+			var clr *color.RGBA
+			if cellColor, ok := c.colors[i]; ok {
+				clr = &cellColor
+			}
+			if clr == nil && current != nil {
+				buf.WriteString("\x1b[0m")
+			} else if clr != nil && (current == nil || *clr != *current) {
+				fmt.Fprintf(buf, "\x1b[38;2;%d;%d;%dm", clr.R, clr.G, clr.B)
+			}
+			current = clr
+
+			if r, ok := c.texts[i]; ok {
+				buf.WriteRune(r)
+			} else if c.dots[i] != 0 {
+				buf.WriteRune(rune(0x2800 + int(c.dots[i])))
+			} else {
+				buf.WriteRune(' ')
+			}
+		}
+		if current != nil {
+			buf.WriteString("\x1b[0m")
+		}
+		if row < c.rows-1 {
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.String()
+}
+
+func round(f float64) int {
+	return int(math.Floor(f + 0.5))
+}
@@ -0,0 +1,59 @@
+package tui
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestCanvasDots(test *testing.T) {
+	c := NewCanvas(2, 1)
+	c.Set(0, 0, nil)
+	c.Set(1, 3, nil)
+	c.Set(2, 1, nil)
+	c.Set(5, 0, nil) // outside
+	if str := c.String(); str != "⢁⠂" {
+		test.Errorf("unexpected dots %q", str)
+	}
+
+	c = NewCanvas(3, 2)
+	c.Line(0, 0, 5, 7, nil)
+	lines := strings.Split(c.String(), "\n")
+	if len(lines) != 2 {
+		test.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+	if lines[0][0] == ' ' || lines[1][len(lines[1])-1] == ' ' {
+		test.Errorf("line should go from corner to corner %q", lines)
+	}
+}
+
+func TestCanvasText(test *testing.T) {
+	c := NewCanvas(5, 1)
+	c.Rect(0, 0, 9, 3, true, nil)
+	c.Text(5, 1, "ab", nil)
+	if str := c.String(); str != "⣿ab⣿⣿" {
+		test.Errorf("text should cover dots %q", str)
+	}
+	c.Text(-10, 1, "x", nil)
+	c.Text(1, 10, "x", nil)
+	if str := c.String(); str != "⣿ab⣿⣿" {
+		test.Errorf("text outside should be ignored %q", str)
+	}
+}
+
+func TestCanvasColors(test *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+	c := NewCanvas(3, 1)
+	c.Set(0, 0, &red)
+	c.Set(2, 0, &red)
+	c.Set(4, 0, nil)
+	expected := "\x1b[38;2;255;0;0m⠁⠁\x1b[0m⠁"
+	if str := c.String(); str != expected {
+		test.Errorf("expected %q, got %q", expected, str)
+	}
+	c.Colorize(4, 0, 5, 3, &red)
+	expected = "\x1b[38;2;255;0;0m⠁⠁⠁\x1b[0m"
+	if str := c.String(); str != expected {
+		test.Errorf("expected %q, got %q", expected, str)
+	}
+}
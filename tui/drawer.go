@@ -0,0 +1,155 @@
+package tui
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+
+	"git.yo2.cz/drahoslav/penego/draw"
+)
+
+// Drawer draws net to canvas
+// net is scaled so that area given by its bounds fits the canvas
+type Drawer struct {
+	*Canvas
+	scale  float64  // dots per unit of net
+	origin draw.Pos // point of net drawn in top left corner
+	style  draw.Style
+}
+
+// NewDrawer returns drawer to canvas of given size in cells,
+// which shows area of net between left, top, right and bottom
+func NewDrawer(cols, rows int, left, top, right, bottom float64) *Drawer {
+	canvas := NewCanvas(cols, rows)
+	width, height := canvas.Size()
+	const margin = 2 // dots
+	scale := math.Min(
+		float64(width-2*margin)/math.Max(right-left, 1),
+		float64(height-2*margin)/math.Max(bottom-top, 1),
+	)
+	// center net in canvas
+	origin := draw.Pos{
+		(left+right)/2 - float64(width)/2/scale,
+		(top+bottom)/2 - float64(height)/2/scale,
+	}
+	return &Drawer{canvas, scale, origin, draw.DefaultStyle}
+}
+
+// returns position of dot for position in net
+func (d *Drawer) dot(pos draw.Pos) (float64, float64) {
+	return (pos.X - d.origin.X) * d.scale, (pos.Y - d.origin.Y) * d.scale
+}
+
+// returns colour of current style, nil if it is foreground colour of theme
+// so that default colour of terminal is used instead
+func (d *Drawer) color() *color.RGBA {
+	clr := d.style.Color()
+	if clr == draw.CurrentTheme.Foreground {
+		return nil
+	}
+	return &clr
+}
+
+func (d *Drawer) SetStyle(style draw.Style) {
+	d.style = style
+}
+
+func (d *Drawer) DrawPlace(pos draw.Pos, n int, description string) {
+	x, y := d.dot(pos)
+	r := draw.PLACE_RADIUS * d.scale
+	d.Circle(x, y, r, d.color())
+	if n > 0 {
+		d.Text(x, y, strconv.Itoa(n), d.color())
+	}
+	if description != "" {
+		d.Text(x, y-r-4, description, d.color())
+	}
+}
+
+func (d *Drawer) DrawTransition(pos draw.Pos, attrs, description string) {
+	x, y := d.dot(pos)
+	w, h := draw.TRANSITION_WIDTH*d.scale, draw.TRANSITION_HEIGHT*d.scale
+	d.Rect(x-w/2, y-h/2, x+w/2, y+h/2, w < 3, d.color())
+	if attrs != "" {
+		d.Text(x, y+h/2+4, attrs, d.color())
+	}
+	if description != "" {
+		d.Text(x, y-h/2-4, description, d.color())
+	}
+}
+
+func (d *Drawer) DrawLabel(pos draw.Pos, text string) {
+	x, y := d.dot(pos)
+	d.Text(x, y, text, d.color())
+}
+
+func (d *Drawer) DrawTransitionState(pos draw.Pos, enabled bool, progress float64) {
+	x, y := d.dot(pos)
+	w, h := draw.TRANSITION_WIDTH*d.scale, draw.TRANSITION_HEIGHT*d.scale
+	active := draw.CurrentTheme.Active
+	if progress >= 0 {
+		progress = math.Min(progress, 1)
+		d.Rect(x-w/2, y+h/2-h*progress, x+w/2, y+h/2, true, &active)
+	}
+	if enabled {
+		d.Colorize(x-w/2, y-h/2, x+w/2, y+h/2, &active)
+	}
+}
+
+// draws polyline of arc, which starts and ends on edges of nodes of given radiuses
+// returns last two points of drawn line
+func (d *Drawer) arcLine(path []draw.Pos, startRadius, endRadius float64) (ax, ay, bx, by float64) {
+	shorten := func(ax, ay, bx, by, radius float64) (float64, float64) {
+		length := math.Hypot(bx-ax, by-ay)
+		if radius*d.scale >= length {
+			return ax, ay
+		}
+		return ax + (bx-ax)*radius*d.scale/length, ay + (by-ay)*radius*d.scale/length
+	}
+	for i := 0; i < len(path)-1; i++ {
+		ax, ay = d.dot(path[i])
+		bx, by = d.dot(path[i+1])
+		if i == 0 {
+			ax, ay = shorten(ax, ay, bx, by, startRadius)
+		}
+		if i == len(path)-2 {
+			bx, by = shorten(bx, by, ax, ay, endRadius)
+		}
+		d.Line(ax, ay, bx, by, d.color())
+	}
+	return
+}
+
+func (d *Drawer) arrow(path []draw.Pos, startRadius, endRadius float64, weight int) {
+	if len(path) < 2 {
+		return
+	}
+	ax, ay, bx, by := d.arcLine(path, startRadius, endRadius)
+	angle := math.Atan2(by-ay, bx-ax)
+	const head, spread = 3.0, math.Pi / 6
+	for _, side := range []float64{-spread, +spread} {
+		d.Line(bx, by, bx-head*math.Cos(angle+side), by-head*math.Sin(angle+side), d.color())
+	}
+	if weight > 1 {
+		mx, my := d.dot(path[len(path)/2-1])
+		nx, ny := d.dot(path[len(path)/2])
+		d.Text((mx+nx)/2, (my+ny)/2-4, strconv.Itoa(weight), d.color())
+	}
+}
+
+func (d *Drawer) DrawInArc(path []draw.Pos, weight int) {
+	d.arrow(path, draw.PLACE_RADIUS, draw.TRANSITION_WIDTH/2, weight)
+}
+
+func (d *Drawer) DrawOutArc(path []draw.Pos, weight int) {
+	d.arrow(path, draw.TRANSITION_WIDTH/2, draw.PLACE_RADIUS, weight)
+}
+
+func (d *Drawer) DrawInhibitorArc(path []draw.Pos) {
+	if len(path) < 2 {
+		return
+	}
+	const r = 1.5 // dots
+	_, _, bx, by := d.arcLine(path, draw.PLACE_RADIUS, draw.TRANSITION_WIDTH/2+2*r/d.scale)
+	d.Circle(bx, by, r, d.color())
+}
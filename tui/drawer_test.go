@@ -0,0 +1,38 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"git.yo2.cz/drahoslav/penego/compose"
+	"git.yo2.cz/drahoslav/penego/net"
+)
+
+func TestDrawer(test *testing.T) {
+	network, _ := net.Parse(`
+		p (3)
+		q ()
+		----
+		p -> t[] -> q
+	`)
+	comp := compose.New()
+	comp.Move(network.Places()[0], 0, 0)
+	comp.Move(network.Transitions()[0], 90, 0)
+	comp.Move(network.Places()[1], 180, 0)
+
+	left, top, right, bottom := comp.Bounds()
+	drawer := NewDrawer(40, 10, left, top, right, bottom)
+	comp.DrawWith(drawer)
+	str := drawer.String()
+
+	lines := strings.Split(str, "\n")
+	if len(lines) != 10 {
+		test.Errorf("expected 10 lines, got %d", len(lines))
+	}
+	if !strings.Contains(str, "3") {
+		test.Errorf("tokens of place should be drawn\n%s", str)
+	}
+	if strings.Count(lines[0], " ") != 40 || strings.Count(lines[9], " ") != 40 {
+		test.Errorf("net should fit with margin\n%s", str)
+	}
+}
@@ -0,0 +1,123 @@
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Terminal is terminal switched to mode, where keys are read immediately and are not echoed
+// it is controlled by stty, so it works on unix-like systems only
+type Terminal struct {
+	saved string // stty settings to restore
+	keys  chan string
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// Open switches terminal to raw mode and starts reading keys
+func Open() (*Terminal, error) {
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("terminal is not supported: %s", err)
+	}
+	if _, err := stty("-icanon", "-echo", "-isig", "min", "1"); err != nil {
+		return nil, err
+	}
+	term := &Terminal{saved, make(chan string)}
+	fmt.Print("\x1b[?25l\x1b[2J") // hide cursor, clear screen
+	go term.readKeys(bufio.NewReader(os.Stdin))
+	return term, nil
+}
+
+// Close restores previous mode of terminal
+func (term *Terminal) Close() {
+	fmt.Print("\x1b[0m\x1b[2J\x1b[H\x1b[?25h") // clear screen, show cursor
+	stty(term.saved)
+}
+
+// Size returns number of columns and rows of terminal
+func (term *Terminal) Size() (cols, rows int) {
+	cols, rows = 80, 24
+	if out, err := stty("size"); err == nil {
+		fmt.Sscanf(out, "%d %d", &rows, &cols)
+	}
+	return
+}
+
+// Render draws content from top left corner of terminal
+func (term *Terminal) Render(content string) {
+	lines := strings.Split(content, "\n")
+	fmt.Print("\x1b[H" + strings.Join(lines, "\x1b[K\r\n") + "\x1b[K")
+}
+
+// Keys returns channel of pressed keys, named as keys of gui (e.g. Q, space, right, home)
+// it is closed when input ends
+func (term *Terminal) Keys() <-chan string {
+	return term.keys
+}
+
+func (term *Terminal) readKeys(reader io.ByteReader) {
+	defer close(term.keys)
+	for {
+		key, err := readKey(reader)
+		if err != nil {
+			return
+		}
+		if key != "" {
+			term.keys <- key
+		}
+	}
+}
+
+// reads single key, escape sequences of special keys are translated to their names
+func readKey(reader io.ByteReader) (string, error) {
+	b, err := reader.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case b == ' ':
+		return "space", nil
+	case b == 0x03: // ctrl+c, signals are disabled to restore terminal on quit
+		return "Q", nil
+	case b == 0x1b:
+		seq := []byte{}
+		for {
+			next, err := reader.ReadByte()
+			if err != nil {
+				return "", err
+			}
+			seq = append(seq, next)
+			// sequences end by letter or ~, except of their [ or O prefix
+			if len(seq) > 1 && (next >= 'A' && next <= 'Z' || next == '~' || next >= 'a' && next <= 'z') {
+				break
+			}
+			if len(seq) == 1 && next != '[' && next != 'O' {
+				return "escape", nil
+			}
+		}
+		switch string(seq) {
+		case "[C", "OC":
+			return "right", nil
+		case "[D", "OD":
+			return "left", nil
+		case "[H", "OH", "[1~", "[7~":
+			return "home", nil
+		}
+		return "", nil
+	case b >= 'a' && b <= 'z':
+		return strings.ToUpper(string(b)), nil
+	case b >= 'A' && b <= 'Z', b >= '0' && b <= '9':
+		return string(b), nil
+	}
+	return "", nil
+}
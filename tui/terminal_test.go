@@ -0,0 +1,23 @@
+package tui
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestReadKey(test *testing.T) {
+	reader := bytes.NewBufferString("q \x1b[C\x1bOD\x1b[H\x1b[1~\x1b[A\x03")
+	expected := []string{"Q", "space", "right", "left", "home", "home", "", "Q"}
+	for _, exp := range expected {
+		key, err := readKey(reader)
+		if err != nil {
+			test.Fatal(err)
+		}
+		if key != exp {
+			test.Errorf("expected key %q, got %q", exp, key)
+		}
+	}
+	if _, err := readKey(reader); err == nil {
+		test.Errorf("expected error at end of input")
+	}
+}